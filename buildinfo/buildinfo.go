@@ -0,0 +1,56 @@
+// Package buildinfo describes the running binary. Commit and BuildTime are set at
+// link time:
+//
+//	go build -ldflags "-X github.com/harshitrajsinha/goserver-vanmango/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/harshitrajsinha/goserver-vanmango/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/vanmango
+//
+// When they are not set the VCS details recorded by the Go toolchain are used.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	Commit    string
+	BuildTime string
+)
+
+type Info struct {
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build-time"`
+	GoVersion string `json:"go-version"`
+	// Set when the binary was built from a tree with uncommitted changes
+	Modified bool `json:"modified,omitempty"`
+}
+
+// Get returns the build details, unknown values are reported as "unknown"
+func Get() Info {
+	info := Info{Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildTime == "" {
+		info.BuildTime = "unknown"
+	}
+	return info
+}
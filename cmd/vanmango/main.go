@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+
+	"github.com/harshitrajsinha/goserver-vanmango/config"
+	"github.com/harshitrajsinha/goserver-vanmango/driver"
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
+	_ "github.com/lib/pq"
+)
+
+const usage = `Usage: vanmango <command> [arguments]
+
+Commands:
+  serve                 Apply pending migrations, create the AUTH_USER account and start the API server (default)
+  migrate up            Apply all pending migrations
+  migrate down [steps]  Roll back the latest migrations (default 1 step)
+  migrate status        Show applied and pending migrations
+  seed                  Load the sample van and engine catalogue
+`
+
+func main() {
+
+	// Settings are validated by each command for the parts it needs
+	cfg, err := config.Read()
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.SetDefault(logging.New(os.Stderr, cfg.Log.Level))
+
+	command := "serve"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	switch command {
+	case "serve":
+		err = serve(cfg)
+	case "migrate":
+		err = runMigrate(cfg.Database, os.Args[2:])
+	case "seed":
+		err = runSeed(cfg.Database)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Initialize database connection and return the client, the caller closes it
+func openDB(ctx context.Context, cfg config.DatabaseConfig) (*sql.DB, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	dbClient, err := driver.Open(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	log.Println("Successfully connected to database")
+	return dbClient, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/harshitrajsinha/goserver-vanmango/config"
+	"github.com/harshitrajsinha/goserver-vanmango/migrate"
+)
+
+// Handle `vanmango migrate up|down|status`
+func runMigrate(cfg config.DatabaseConfig, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing migrate subcommand, expected one of up, down, status")
+	}
+
+	ctx := context.Background()
+
+	dbClient, err := openDB(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer dbClient.Close()
+
+	migrator, err := migrate.NewMigrator(dbClient)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		count, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("%d migration(s) applied\n", count)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		count, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		log.Printf("%d migration(s) rolled back\n", count)
+
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+		for _, entry := range status {
+			appliedAt := "pending"
+			if entry.AppliedAt != nil {
+				appliedAt = entry.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(writer, "%d\t%s\t%s\n", entry.Version, entry.Name, appliedAt)
+		}
+		return writer.Flush()
+
+	default:
+		return fmt.Errorf("unknown migrate subcommand %q, expected one of up, down, status", args[0])
+	}
+
+	return nil
+}
+
+// Handle `vanmango seed`
+func runSeed(cfg config.DatabaseConfig) error {
+	ctx := context.Background()
+
+	dbClient, err := openDB(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer dbClient.Close()
+
+	migrator, err := migrate.NewMigrator(dbClient)
+	if err != nil {
+		return err
+	}
+	if err := migrator.Seed(ctx); err != nil {
+		return err
+	}
+	log.Println("Sample data seeded successfully!")
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/harshitrajsinha/goserver-vanmango/config"
+	"github.com/harshitrajsinha/goserver-vanmango/server"
+	"github.com/harshitrajsinha/goserver-vanmango/tracing"
+)
+
+// Time allowed for in-flight requests to finish once a shutdown signal arrives
+const shutdownTimeout = 15 * time.Second
+
+// Run the API server until SIGINT/SIGTERM, then drain and close the database
+func serve(cfg *config.Config) error {
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	// flush buffered spans last, after the server and database are closed
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Println("error flushing traces: ", err)
+		}
+	}()
+
+	var dbClient *sql.DB
+	var stores server.Stores
+	if cfg.Store.Driver == config.StoreDriverMemory {
+		stores = server.NewMemoryStores()
+		log.Println("Using in-memory stores, data is lost on shutdown")
+	} else {
+		dbClient, err = openDB(context.Background(), cfg.Database)
+		if err != nil {
+			return err
+		}
+
+		// close db connection once the server has drained
+		defer func() {
+			if err := dbClient.Close(); err != nil {
+				log.Println("error closing database connection: ", err)
+			} else {
+				log.Println("Database connection closed")
+			}
+		}()
+		stores = server.NewPostgresStores(dbClient)
+	}
+
+	// Apply pending migrations and create the initial account
+	if err := server.Bootstrap(context.Background(), cfg, dbClient, stores); err != nil {
+		return err
+	}
+
+	port := cfg.Port
+
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           server.NewRouter(cfg, dbClient, stores),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+
+	// Stop on Ctrl+C locally or SIGTERM from the orchestrator
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Println("Server listening on PORT ", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
+		log.Println("Shutdown signal received, draining requests")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("graceful shutdown failed: %v", err)
+	}
+	log.Println("Server stopped")
+	return nil
+}
@@ -0,0 +1,465 @@
+// Package config loads the server settings once at startup. Values come from
+// built-in defaults, then an optional YAML file, then the environment (including
+// a .env file in development), each layer overriding the one before it.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harshitrajsinha/goserver-vanmango/signing"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Values accepted for the store driver
+const (
+	StoreDriverPostgres = "postgres"
+	StoreDriverMemory   = "memory"
+)
+
+// Values accepted for the trace exporter
+const (
+	TracingExporterNone   = "none"
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+)
+
+type Config struct {
+	Port string `yaml:"port"`
+	// Take the client address from X-Forwarded-For, only safe behind a proxy that sets it
+	TrustProxy bool            `yaml:"trust-proxy"`
+	Store      StoreConfig     `yaml:"store"`
+	Database   DatabaseConfig  `yaml:"database"`
+	Auth       AuthConfig      `yaml:"auth"`
+	CORS       CORSConfig      `yaml:"cors"`
+	RateLimit  RateLimitConfig `yaml:"rate-limit"`
+	Features   FeatureFlags    `yaml:"features"`
+	Log        LogConfig       `yaml:"log"`
+	Tracing    TracingConfig   `yaml:"tracing"`
+}
+
+type StoreConfig struct {
+	Driver string `yaml:"driver"`
+}
+
+// Connection and pool settings for Postgres, zero pool values keep the database/sql defaults
+type DatabaseConfig struct {
+	URL             string        `yaml:"url"`
+	MaxOpenConns    int           `yaml:"max-open-conns"`
+	MaxIdleConns    int           `yaml:"max-idle-conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn-max-lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn-max-idle-time"`
+	// How long startup keeps retrying an unreachable database
+	ConnectTimeout time.Duration `yaml:"connect-timeout"`
+}
+
+type AuthConfig struct {
+	// Private keys access tokens are signed with, see package signing for the format
+	SigningKeys signing.KeySet `yaml:"signing-keys"`
+	// iss and aud of access tokens, tokens naming another issuer or audience are rejected
+	Issuer          string        `yaml:"issuer"`
+	Audience        string        `yaml:"audience"`
+	AccessTokenTTL  time.Duration `yaml:"access-token-ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh-token-ttl"`
+	// Account created with the admin role on startup, skipped when empty
+	AdminUser     string        `yaml:"admin-user"`
+	AdminPassword string        `yaml:"admin-password"`
+	Lockout       LockoutConfig `yaml:"lockout"`
+}
+
+// Failed login tracking. Once a username or client address reaches its limit it
+// is locked out for Duration, doubled with every further failure up to MaxDuration.
+type LockoutConfig struct {
+	MaxAttempts      int           `yaml:"max-attempts"`
+	MaxAttemptsPerIP int           `yaml:"max-attempts-per-ip"`
+	Duration         time.Duration `yaml:"duration"`
+	MaxDuration      time.Duration `yaml:"max-duration"`
+}
+
+// Cross-origin access for browser clients, CORS is off while AllowedOrigins is empty
+type CORSConfig struct {
+	AllowedOrigins   []string      `yaml:"allowed-origins"`
+	AllowedMethods   []string      `yaml:"allowed-methods"`
+	AllowedHeaders   []string      `yaml:"allowed-headers"`
+	AllowCredentials bool          `yaml:"allow-credentials"`
+	MaxAge           time.Duration `yaml:"max-age"`
+}
+
+// Per client token buckets. Public applies to the unauthenticated API routes,
+// Protected to the authenticated ones. Auth is counted per client address on the
+// authenticated routes before credentials are checked, so rejected credentials are
+// limited too. Probes and /metrics are never limited.
+type RateLimitConfig struct {
+	Public    RateLimit `yaml:"public"`
+	Protected RateLimit `yaml:"protected"`
+	Auth      RateLimit `yaml:"auth"`
+}
+
+// Requests allowed per Period, in bursts of up to Requests. Zero Requests turns the limit off.
+type RateLimit struct {
+	Requests int           `yaml:"requests"`
+	Period   time.Duration `yaml:"period"`
+}
+
+type LogConfig struct {
+	// One of debug, info, warn or error
+	Level slog.Level `yaml:"level"`
+}
+
+type TracingConfig struct {
+	Exporter string `yaml:"exporter"`
+	// OTLP/HTTP collector URL, e.g. http://localhost:4318. The standard
+	// OTEL_EXPORTER_OTLP_* variables are used when empty.
+	OTLPEndpoint string `yaml:"otlp-endpoint"`
+	// File the stdout exporter appends to instead of standard output
+	File string `yaml:"file"`
+	// Share of new traces that are recorded, from 0 to 1
+	SampleRatio float64 `yaml:"sample-ratio"`
+}
+
+// Switches for optional parts of the API
+type FeatureFlags struct {
+	Registration bool `yaml:"registration"`
+	Search       bool `yaml:"search"`
+	// Serve Prometheus metrics on /metrics
+	Metrics bool `yaml:"metrics"`
+}
+
+// Default returns the settings used when nothing else is configured.
+// Signing keys have no default and must be provided unless the memory driver is used.
+func Default() *Config {
+	return &Config{
+		Port: "8080",
+		Store: StoreConfig{
+			Driver: StoreDriverPostgres,
+		},
+		Database: DatabaseConfig{
+			MaxOpenConns:    10,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			ConnectTimeout:  30 * time.Second,
+		},
+		Auth: AuthConfig{
+			Issuer:          "vanmango",
+			Audience:        "vanmango-api",
+			AccessTokenTTL:  30 * time.Minute,
+			RefreshTokenTTL: 7 * 24 * time.Hour,
+			Lockout: LockoutConfig{
+				MaxAttempts:      5,
+				MaxAttemptsPerIP: 20,
+				Duration:         time.Minute,
+				MaxDuration:      time.Hour,
+			},
+		},
+		Features: FeatureFlags{
+			Registration: true,
+			Search:       true,
+			Metrics:      true,
+		},
+		CORS: CORSConfig{
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+			AllowedHeaders: []string{"Authorization", "Content-Type", "X-API-Key", "X-Request-ID"},
+			MaxAge:         10 * time.Minute,
+		},
+		RateLimit: RateLimitConfig{
+			Public:    RateLimit{Requests: 300, Period: time.Minute},
+			Protected: RateLimit{Requests: 60, Period: time.Minute},
+			Auth:      RateLimit{Requests: 300, Period: time.Minute},
+		},
+		Log: LogConfig{
+			Level: slog.LevelInfo,
+		},
+		Tracing: TracingConfig{
+			Exporter:    TracingExporterNone,
+			SampleRatio: 1,
+		},
+	}
+}
+
+// Load reads the configuration and validates all of it, used before serving
+func Load() (*Config, error) {
+	cfg, err := Read()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Read layers .env, the YAML file named by CONFIG_FILE if set, and the environment
+// over the defaults without validating. Commands that only need part of the
+// config, such as migrations, validate that part themselves.
+func Read() (*Config, error) {
+
+	// .env never overrides variables that are already set
+	_ = godotenv.Load()
+
+	cfg := Default()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %v", err)
+	}
+	defer file.Close()
+
+	// unknown keys are rejected so a typo does not silently fall back to a default
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parsing config file %s: %v", path, err)
+	}
+	return nil
+}
+
+// Overlay every variable that is set onto the config
+func (c *Config) loadEnv() error {
+	var errs []error
+
+	setString := func(name string, dest *string) {
+		if value, ok := os.LookupEnv(name); ok {
+			*dest = value
+		}
+	}
+	setInt := func(name string, dest *int) {
+		if value, ok := os.LookupEnv(name); ok {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be a whole number", name))
+				return
+			}
+			*dest = parsed
+		}
+	}
+	setDuration := func(name string, dest *time.Duration) {
+		if value, ok := os.LookupEnv(name); ok {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be a duration such as 30m or 24h", name))
+				return
+			}
+			*dest = parsed
+		}
+	}
+	setFloat := func(name string, dest *float64) {
+		if value, ok := os.LookupEnv(name); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be a number", name))
+				return
+			}
+			*dest = parsed
+		}
+	}
+	setBool := func(name string, dest *bool) {
+		if value, ok := os.LookupEnv(name); ok {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be true or false", name))
+				return
+			}
+			*dest = parsed
+		}
+	}
+	setList := func(name string, dest *[]string) {
+		if value, ok := os.LookupEnv(name); ok {
+			*dest = splitList(value)
+		}
+	}
+
+	setString("PORT", &c.Port)
+	setBool("TRUST_PROXY", &c.TrustProxy)
+	setString("STORE_DRIVER", &c.Store.Driver)
+
+	setString("DB_URL", &c.Database.URL)
+	setInt("DB_MAX_OPEN_CONNS", &c.Database.MaxOpenConns)
+	setInt("DB_MAX_IDLE_CONNS", &c.Database.MaxIdleConns)
+	setDuration("DB_CONN_MAX_LIFETIME", &c.Database.ConnMaxLifetime)
+	setDuration("DB_CONN_MAX_IDLE_TIME", &c.Database.ConnMaxIdleTime)
+	setDuration("DB_CONNECT_TIMEOUT", &c.Database.ConnectTimeout)
+
+	if value, ok := os.LookupEnv("JWT_SIGNING_KEYS"); ok {
+		if err := c.Auth.SigningKeys.UnmarshalText([]byte(value)); err != nil {
+			errs = append(errs, fmt.Errorf("JWT_SIGNING_KEYS must hold PEM encoded RSA or Ed25519 private keys: %v", err))
+		}
+	}
+	setString("JWT_ISSUER", &c.Auth.Issuer)
+	setString("JWT_AUDIENCE", &c.Auth.Audience)
+	setDuration("JWT_ACCESS_TTL", &c.Auth.AccessTokenTTL)
+	setDuration("JWT_REFRESH_TTL", &c.Auth.RefreshTokenTTL)
+	setString("AUTH_USER", &c.Auth.AdminUser)
+	setString("AUTH_PASS", &c.Auth.AdminPassword)
+	setInt("LOGIN_MAX_ATTEMPTS", &c.Auth.Lockout.MaxAttempts)
+	setInt("LOGIN_MAX_ATTEMPTS_PER_IP", &c.Auth.Lockout.MaxAttemptsPerIP)
+	setDuration("LOGIN_LOCKOUT", &c.Auth.Lockout.Duration)
+	setDuration("LOGIN_MAX_LOCKOUT", &c.Auth.Lockout.MaxDuration)
+
+	setList("CORS_ALLOWED_ORIGINS", &c.CORS.AllowedOrigins)
+	setList("CORS_ALLOWED_METHODS", &c.CORS.AllowedMethods)
+	setList("CORS_ALLOWED_HEADERS", &c.CORS.AllowedHeaders)
+	setBool("CORS_ALLOW_CREDENTIALS", &c.CORS.AllowCredentials)
+	setDuration("CORS_MAX_AGE", &c.CORS.MaxAge)
+
+	setInt("RATE_LIMIT_PUBLIC_REQUESTS", &c.RateLimit.Public.Requests)
+	setDuration("RATE_LIMIT_PUBLIC_PERIOD", &c.RateLimit.Public.Period)
+	setInt("RATE_LIMIT_PROTECTED_REQUESTS", &c.RateLimit.Protected.Requests)
+	setDuration("RATE_LIMIT_PROTECTED_PERIOD", &c.RateLimit.Protected.Period)
+	setInt("RATE_LIMIT_AUTH_REQUESTS", &c.RateLimit.Auth.Requests)
+	setDuration("RATE_LIMIT_AUTH_PERIOD", &c.RateLimit.Auth.Period)
+
+	setBool("FEATURE_REGISTRATION", &c.Features.Registration)
+	setBool("FEATURE_SEARCH", &c.Features.Search)
+	setBool("FEATURE_METRICS", &c.Features.Metrics)
+
+	setString("TRACING_EXPORTER", &c.Tracing.Exporter)
+	setString("TRACING_OTLP_ENDPOINT", &c.Tracing.OTLPEndpoint)
+	setString("TRACING_FILE", &c.Tracing.File)
+	setFloat("TRACING_SAMPLE_RATIO", &c.Tracing.SampleRatio)
+
+	if value, ok := os.LookupEnv("LOG_LEVEL"); ok {
+		if err := c.Log.Level.UnmarshalText([]byte(value)); err != nil {
+			errs = append(errs, errors.New("LOG_LEVEL must be one of debug, info, warn or error"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Split a comma separated list, dropping blanks
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Validate reports every invalid setting at once so a bad deploy fails on boot
+func (c *Config) Validate() error {
+	var errs []error
+
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, errors.New("PORT must be a number within the range of 1-65535"))
+	}
+
+	switch c.Store.Driver {
+	case StoreDriverPostgres:
+		if err := c.Database.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	case StoreDriverMemory:
+	default:
+		errs = append(errs, fmt.Errorf("STORE_DRIVER must be one of following - ['%s', '%s']", StoreDriverPostgres, StoreDriverMemory))
+	}
+
+	// the memory driver falls back to a temporary key, real deployments need keys that outlive the process
+	if c.Auth.SigningKeys.Len() == 0 && c.Store.Driver != StoreDriverMemory {
+		errs = append(errs, errors.New("JWT_SIGNING_KEYS is required"))
+	}
+	if _, err := c.Auth.SigningKeys.Signer(time.Now()); c.Auth.SigningKeys.Len() > 0 && err != nil {
+		errs = append(errs, errors.New("JWT_SIGNING_KEYS must hold a key whose Active-From has passed"))
+	}
+	if strings.TrimSpace(c.Auth.Issuer) == "" || strings.TrimSpace(c.Auth.Audience) == "" {
+		errs = append(errs, errors.New("JWT_ISSUER and JWT_AUDIENCE are required"))
+	}
+	if c.Auth.AccessTokenTTL <= 0 || c.Auth.RefreshTokenTTL <= 0 {
+		errs = append(errs, errors.New("JWT_ACCESS_TTL and JWT_REFRESH_TTL must be greater than 0"))
+	}
+	if (c.Auth.AdminUser == "") != (c.Auth.AdminPassword == "") {
+		errs = append(errs, errors.New("AUTH_USER and AUTH_PASS must be set together"))
+	}
+	if c.Auth.Lockout.MaxAttempts < 1 || c.Auth.Lockout.MaxAttemptsPerIP < 1 {
+		errs = append(errs, errors.New("LOGIN_MAX_ATTEMPTS and LOGIN_MAX_ATTEMPTS_PER_IP must be at least 1"))
+	}
+	if c.Auth.Lockout.Duration <= 0 || c.Auth.Lockout.MaxDuration < c.Auth.Lockout.Duration {
+		errs = append(errs, errors.New("LOGIN_LOCKOUT must be greater than 0 and not greater than LOGIN_MAX_LOCKOUT"))
+	}
+
+	switch c.Tracing.Exporter {
+	case TracingExporterNone, TracingExporterOTLP, TracingExporterStdout:
+	default:
+		errs = append(errs, fmt.Errorf("TRACING_EXPORTER must be one of following - ['%s', '%s', '%s']", TracingExporterNone, TracingExporterOTLP, TracingExporterStdout))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, errors.New("TRACING_SAMPLE_RATIO must be within the range of 0-1"))
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			errs = append(errs, fmt.Errorf("CORS origin %q must be * or start with http:// or https://", origin))
+		}
+		// browsers refuse credentialed responses for a wildcard origin
+		if origin == "*" && c.CORS.AllowCredentials {
+			errs = append(errs, errors.New("CORS_ALLOW_CREDENTIALS cannot be used with the * origin, list the origins instead"))
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		errs = append(errs, errors.New("CORS_MAX_AGE must not be negative"))
+	}
+
+	limits := []struct {
+		name  string
+		limit RateLimit
+	}{
+		{"PUBLIC", c.RateLimit.Public},
+		{"PROTECTED", c.RateLimit.Protected},
+		{"AUTH", c.RateLimit.Auth},
+	}
+	for _, l := range limits {
+		if l.limit.Requests < 0 {
+			errs = append(errs, fmt.Errorf("RATE_LIMIT_%s_REQUESTS must not be negative", l.name))
+		}
+		if l.limit.Requests > 0 && l.limit.Period < time.Second {
+			errs = append(errs, fmt.Errorf("RATE_LIMIT_%s_PERIOD must be at least 1s", l.name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validate checks the settings needed to open a Postgres connection
+func (d DatabaseConfig) Validate() error {
+	var errs []error
+
+	if d.URL == "" {
+		errs = append(errs, errors.New("DB_URL is required"))
+	}
+	if d.MaxOpenConns < 0 || d.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("DB_MAX_OPEN_CONNS and DB_MAX_IDLE_CONNS must not be negative"))
+	}
+	if d.MaxOpenConns > 0 && d.MaxIdleConns > d.MaxOpenConns {
+		errs = append(errs, errors.New("DB_MAX_IDLE_CONNS must not be greater than DB_MAX_OPEN_CONNS"))
+	}
+	if d.ConnMaxLifetime < 0 || d.ConnMaxIdleTime < 0 {
+		errs = append(errs, errors.New("DB_CONN_MAX_LIFETIME and DB_CONN_MAX_IDLE_TIME must not be negative"))
+	}
+	if d.ConnectTimeout <= 0 {
+		errs = append(errs, errors.New("DB_CONNECT_TIMEOUT must be greater than 0"))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// PEM encoded Ed25519 signing key with the given Key-ID
+func testSigningKey(t *testing.T, id string) string {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Headers: map[string]string{"Key-ID": id}, Bytes: der}))
+}
+
+func TestReadLayersFileAndEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	file := `
+port: "9090"
+store:
+  driver: memory
+auth:
+  signing-keys: |
+    ` + strings.ReplaceAll(strings.TrimSpace(testSigningKey(t, "from-file")), "\n", "\n    ") + `
+  access-token-ttl: 10m
+features:
+  search: false
+log:
+  level: debug
+`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("JWT_SIGNING_KEYS", testSigningKey(t, "from-env"))
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example.com, ,http://b.example.com")
+
+	cfg, err := Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if cfg.Port != "9090" || cfg.Store.Driver != StoreDriverMemory || cfg.Auth.AccessTokenTTL != 10*time.Minute {
+		t.Fatalf("file values not applied: %+v", cfg)
+	}
+	if key, err := cfg.Auth.SigningKeys.Signer(time.Now()); err != nil || key.ID != "from-env" || cfg.Auth.SigningKeys.Len() != 1 {
+		t.Fatalf("environment should override the file, got signing key %+v (%v)", key, err)
+	}
+	if cfg.Log.Level != slog.LevelDebug {
+		t.Fatalf("got log level %s, want debug", cfg.Log.Level)
+	}
+	if cfg.Features.Search || !cfg.Features.Registration {
+		t.Fatalf("unexpected feature flags: %+v", cfg.Features)
+	}
+	if cfg.Auth.RefreshTokenTTL != Default().Auth.RefreshTokenTTL {
+		t.Fatalf("unset values should keep their default, got %s", cfg.Auth.RefreshTokenTTL)
+	}
+	if len(cfg.CORS.AllowedOrigins) != 2 {
+		t.Fatalf("got origins %v", cfg.CORS.AllowedOrigins)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+}
+
+func TestReadRejectsBadValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("prot: \"9090\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	if _, err := Read(); err == nil {
+		t.Fatal("unknown key in config file should be rejected")
+	}
+
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("JWT_ACCESS_TTL", "soon")
+	t.Setenv("LOG_LEVEL", "loud")
+	t.Setenv("JWT_SIGNING_KEYS", "not a key")
+	_, err := Read()
+	for _, want := range []string{"JWT_ACCESS_TTL", "LOG_LEVEL", "JWT_SIGNING_KEYS"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("got %v, want a %s error", err, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	cfg.Port = "0"
+	cfg.Store.Driver = "sqlite"
+	cfg.Auth.AdminUser = "admin"
+	cfg.Auth.Audience = ""
+	cfg.CORS.AllowedOrigins = []string{"example.com"}
+	cfg.Tracing.Exporter = "jaeger"
+	cfg.Tracing.SampleRatio = 2
+	cfg.Auth.Lockout.MaxAttempts = 0
+	cfg.Auth.Lockout.MaxDuration = time.Second
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("invalid config passed validation")
+	}
+	for _, want := range []string{"PORT", "STORE_DRIVER", "JWT_SIGNING_KEYS", "JWT_AUDIENCE", "AUTH_USER", "CORS", "TRACING_EXPORTER", "TRACING_SAMPLE_RATIO", "LOGIN_MAX_ATTEMPTS", "LOGIN_LOCKOUT"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+
+	cfg = Default()
+	if err := cfg.Auth.SigningKeys.UnmarshalText([]byte(testSigningKey(t, "current"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "DB_URL") {
+		t.Fatalf("postgres driver without DB_URL: got %v", err)
+	}
+	cfg.Database.URL = "postgres://localhost/vanmango"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid config rejected: %v", err)
+	}
+}
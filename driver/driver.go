@@ -1,53 +1,77 @@
 package driver
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"os"
+	"log"
 	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/harshitrajsinha/goserver-vanmango/config"
 	_ "github.com/lib/pq"
 )
 
-var db *sql.DB
-var err error
-
-// Initialize database connection
-func InitDB() error {
-
-	// setup connection url
-	_ = godotenv.Load()
-	dbURL := os.Getenv("DB_URL")
-	connStr := dbURL
+// Delay before the second connection attempt, doubled after every failure up to maxRetryDelay
+const (
+	initialRetryDelay = 250 * time.Millisecond
+	maxRetryDelay     = 5 * time.Second
+)
 
-	fmt.Println("Waiting for db startup ...")
-	time.Sleep(5 * time.Second) // wait for 5 seconds to complete database setup
+// Open connects to Postgres with the configured pool limits. The first ping is
+// retried with exponential backoff until it succeeds, ctx is cancelled or
+// cfg.ConnectTimeout runs out, so a database that is still waking up (Neon cold
+// start, container boot) does not fail the instance. The caller closes the client.
+func Open(ctx context.Context, cfg config.DatabaseConfig) (*sql.DB, error) {
 
-	// open database connection
-	db, err = sql.Open("postgres", connStr)
+	// open database connection, no connection is made until the first ping
+	db, err := sql.Open("postgres", cfg.URL)
 	if err != nil {
-		return fmt.Errorf("error opening database: %v", err)
+		return nil, fmt.Errorf("error opening database: %v", err)
 	}
 
-	// connection will be closed in main.go
+	// connection pool limits
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.ConnectTimeout)
+	defer cancel()
 
 	// verify connection
-	err = db.Ping()
-	if err != nil {
-		return fmt.Errorf("error during database Ping(): %v", err)
+	if err := ping(ctx, db); err != nil {
+		db.Close()
+		return nil, err
 	}
-
-	return nil
+	return db, nil
 }
 
-func GetDB() *sql.DB {
-	return db
+// Ping until the database answers or ctx is done
+func ping(ctx context.Context, db *sql.DB) error {
+	delay := initialRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("error during database Ping(), gave up after %d attempt(s): %v", attempt, err)
+		}
+		log.Printf("Database not reachable (attempt %d): %v, retrying in %s\n", attempt, err, delay)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("error during database Ping(), gave up after %d attempt(s): %v", attempt, err)
+		case <-time.After(delay):
+		}
+		delay = nextRetryDelay(delay)
+	}
 }
 
-func CloseDB() error {
-	if err := db.Close(); err != nil {
-		return fmt.Errorf("error closing database connection")
+func nextRetryDelay(delay time.Duration) time.Duration {
+	delay *= 2
+	if delay > maxRetryDelay {
+		return maxRetryDelay
 	}
-	return nil
+	return delay
 }
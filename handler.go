@@ -1,231 +1,94 @@
 package handler
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
-	"runtime/debug"
+	"sync"
 
-	"github.com/gorilla/mux"
+	"github.com/harshitrajsinha/goserver-vanmango/config"
 	"github.com/harshitrajsinha/goserver-vanmango/driver"
-	"github.com/harshitrajsinha/goserver-vanmango/middleware"
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
 	"github.com/harshitrajsinha/goserver-vanmango/routes"
-	apiV1 "github.com/harshitrajsinha/goserver-vanmango/routes/v1"
-	"github.com/harshitrajsinha/goserver-vanmango/service"
-	"github.com/harshitrajsinha/goserver-vanmango/store"
-	"github.com/joho/godotenv"
+	"github.com/harshitrajsinha/goserver-vanmango/server"
+	"github.com/harshitrajsinha/goserver-vanmango/tracing"
 	_ "github.com/lib/pq"
 )
 
-var dbClient *sql.DB
-
-// Function to load data to database via schema file
-func loadDataToDatabase(dbClient *sql.DB, filename string) error {
+var (
+	cfg      *config.Config
+	dbClient *sql.DB
+	stores   server.Stores
+	// set when the instance could not start, every request then gets a 503
+	initErr error
+)
 
-	// Read file content
-	sqlFile, err := os.ReadFile(filename)
-	if err != nil {
-		return err
-	}
+// router is built once per process and reused across invocations
+var (
+	router     http.Handler
+	routerOnce sync.Once
+)
 
-	// Execute file content (queries)
-	_, err = dbClient.Exec(string(sqlFile))
-	if err != nil {
-		return err
+func init() {
+	if initErr = setup(); initErr != nil {
+		slog.Error("Instance failed to start, answering every request with 503", "error", initErr)
 	}
-	return nil
 }
 
-func init() {
+func setup() error {
 
-	var sqlSchemaFile string = "store/schema.sql"
+	// JSON logs from the start, so a bad config is reported like everything else
+	slog.SetDefault(logging.New(os.Stderr, slog.LevelInfo))
 
+	// Read and validate settings once per instance
 	var err error
-
-	// initialize database connection
-	var message string
-	err = driver.InitDB()
+	cfg, err = config.Load()
 	if err != nil {
-		panic(err)
-	} else {
-		log.Println("Successfully connected to database")
+		return err
 	}
-	log.Println(message)
+	slog.SetDefault(logging.New(os.Stderr, cfg.Log.Level))
 
-	// Get instance of database client
-	dbClient = driver.GetDB()
+	// spans are exported in batches, some may be lost when the platform freezes the instance
+	if _, err = tracing.Setup(context.Background(), cfg.Tracing); err != nil {
+		return err
+	}
 
-	// Load data into database
-	err = loadDataToDatabase(dbClient, sqlSchemaFile)
-	if err != nil {
-		panic(err)
+	if cfg.Store.Driver == config.StoreDriverMemory {
+		stores = server.NewMemoryStores()
+		slog.Warn("Using in-memory stores, data is lost when the instance stops")
 	} else {
-		log.Println("SQL file executed successfully!")
+		// initialize database connection, retried while the database wakes up
+		dbClient, err = driver.Open(context.Background(), cfg.Database)
+		if err != nil {
+			return err
+		}
+		slog.Info("Successfully connected to database")
+		stores = server.NewPostgresStores(dbClient)
 	}
+
+	// Apply pending migrations and create the initial account
+	return server.Bootstrap(context.Background(), cfg, dbClient, stores)
 }
 
+// Handler is the Vercel serverless entrypoint
+// Panics inside the router are recovered by its own middleware.
 func Handler(w http.ResponseWriter, r *http.Request) {
 
-	// var err error
-
-	// panic recovery
-	defer func() {
-		if r := recover(); r != nil {
-			log.Println("Error occured: ", r)
-			debug.PrintStack()
-		}
-	}()
-
-	// Load env file data to sys env (development)
-	_ = godotenv.Load()
-
-	if dbClient == nil {
-		log.Println("Error connecting db")
-		os.Exit(1)
-	}
-
-	// close db connection
-	// defer func() {
-	// 	log.Println("hereee")
-	// 	err = driver.CloseDB()
-	// 	if err != nil {
-	// 		panic(err)
-	// 	}
-	// }()
-
-	router := mux.NewRouter()
-	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
+	// the instance stays up and keeps answering, the platform retires it on repeated failures
+	if initErr != nil {
+		slog.Error("Request refused, instance failed to start", "error", initErr)
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{"message": "Server is functioning"})
-	}).Methods("GET")
-
-	// Initialize engine constructors
-	engineStore := store.NewEngineStore(dbClient)
-	engineService := service.NewEngineService(engineStore)
-	engineHandler := apiV1.NewEngineHandler(engineService)
-
-	// Initialize van constructors
-	vanStore := store.NewVanStore(dbClient)
-	vanService := service.NewVanService(vanStore)
-	vanHandler := apiV1.NewVanHandler(vanService)
-
-	// -------------------- Public routes
-
-	// Routes for Engine
-	router.HandleFunc("/api/v1/engine/{id}", engineHandler.GetEngineByID).Methods(http.MethodGet)
-	router.HandleFunc("/api/v1/engines", engineHandler.GetAllEngine).Methods(http.MethodGet)
-	// Routes for Van
-	router.HandleFunc("/api/v1/van/{id}", vanHandler.GetVanByID).Methods(http.MethodGet)
-	router.HandleFunc("/api/v1/vans", vanHandler.GetAllVan).Methods(http.MethodGet)
-
-	// -------------------- Protected routes
-
-	router.HandleFunc("/api/v1/login", routes.LoginHandler).Methods(http.MethodPost)
-	protectedRouter := router.PathPrefix("/").Subrouter()
-	protectedRouter.Use(middleware.AuthMiddleware)
-
-	// Routes for Engine
-	protectedRouter.HandleFunc("/api/v1/engine", engineHandler.CreateEngine).Methods(http.MethodPost)
-	protectedRouter.HandleFunc("/api/v1/engine/{id}", engineHandler.UpdateEngine).Methods(http.MethodPut)
-	protectedRouter.HandleFunc("/api/v1/engine/{id}", engineHandler.UpdateEnginePartial).Methods(http.MethodPatch)
-	protectedRouter.HandleFunc("/api/v1/engine/{id}", engineHandler.DeleteEngine).Methods(http.MethodDelete)
-
-	// Routes for Van
-	protectedRouter.HandleFunc("/api/v1/van", vanHandler.CreateVan).Methods(http.MethodPost)
-	protectedRouter.HandleFunc("/api/v1/van/{id}", vanHandler.UpdateVan).Methods(http.MethodPut)
-	protectedRouter.HandleFunc("/api/v1/van/{id}", vanHandler.UpdateVanPartial).Methods(http.MethodPatch)
-	protectedRouter.HandleFunc("/api/v1/van/{id}", vanHandler.DeleteVan).Methods(http.MethodDelete)
-
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusServiceUnavailable, Message: "Service unavailable"})
+		return
 	}
 
-	log.Println("Server listening on PORT ", port)
+	routerOnce.Do(func() {
+		router = server.NewRouter(cfg, dbClient, stores)
+	})
+
 	router.ServeHTTP(w, r)
 }
-
-// func main() {
-
-// 	var err error
-
-// 	// panic recovery
-// 	defer func() {
-// 		if r := recover(); r != nil {
-// 			log.Println("Error occured: ", r)
-// 			debug.PrintStack()
-// 		}
-// 	}()
-
-// 	// Load env file data to sys env (development)
-// 	_ = godotenv.Load()
-
-// 	if dbClient == nil {
-// 		log.Println("Error connecting db")
-// 		os.Exit(1)
-// 	}
-
-// 	// close db connection
-// 	defer func() {
-// 		log.Println("herer")
-// 		err = driver.CloseDB()
-// 		if err != nil {
-// 			panic(err)
-// 		}
-// 	}()
-
-// 	router := mux.NewRouter()
-// 	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-// 		w.WriteHeader(http.StatusOK)
-// 		w.Header().Set("Content-Type", "application/json")
-// 		json.NewEncoder(w).Encode(map[string]string{"message": "Server is functioning"})
-// 	}).Methods("GET")
-
-// 	// Initialize engine constructors
-// 	engineStore := store.NewEngineStore(dbClient)
-// 	engineService := service.NewEngineService(engineStore)
-// 	engineHandler := apiV1.NewEngineHandler(engineService)
-
-// 	// Initialize van constructors
-// 	vanStore := store.NewVanStore(dbClient)
-// 	vanService := service.NewVanService(vanStore)
-// 	vanHandler := apiV1.NewVanHandler(vanService)
-
-// 	// -------------------- Public routes
-
-// 	// Routes for Engine
-// 	router.HandleFunc("/api/v1/engine/{id}", engineHandler.GetEngineByID).Methods(http.MethodGet)
-// 	router.HandleFunc("/api/v1/engines", engineHandler.GetAllEngine).Methods(http.MethodGet)
-// 	// Routes for Van
-// 	router.HandleFunc("/api/v1/van/{id}", vanHandler.GetVanByID).Methods(http.MethodGet)
-// 	router.HandleFunc("/api/v1/vans", vanHandler.GetAllVan).Methods(http.MethodGet)
-
-// 	// -------------------- Protected routes
-
-// 	router.HandleFunc("/api/v1/login", routes.LoginHandler).Methods(http.MethodPost)
-// 	protectedRouter := router.PathPrefix("/").Subrouter()
-// 	protectedRouter.Use(middleware.AuthMiddleware)
-
-// 	// Routes for Engine
-// 	protectedRouter.HandleFunc("/api/v1/engine", engineHandler.CreateEngine).Methods(http.MethodPost)
-// 	protectedRouter.HandleFunc("/api/v1/engine/{id}", engineHandler.UpdateEngine).Methods(http.MethodPut)
-// 	protectedRouter.HandleFunc("/api/v1/engine/{id}", engineHandler.UpdateEnginePartial).Methods(http.MethodPatch)
-// 	protectedRouter.HandleFunc("/api/v1/engine/{id}", engineHandler.DeleteEngine).Methods(http.MethodDelete)
-
-// 	// Routes for Van
-// 	protectedRouter.HandleFunc("/api/v1/van", vanHandler.CreateVan).Methods(http.MethodPost)
-// 	protectedRouter.HandleFunc("/api/v1/van/{id}", vanHandler.UpdateVan).Methods(http.MethodPut)
-// 	protectedRouter.HandleFunc("/api/v1/van/{id}", vanHandler.UpdateVanPartial).Methods(http.MethodPatch)
-// 	protectedRouter.HandleFunc("/api/v1/van/{id}", vanHandler.DeleteVan).Methods(http.MethodDelete)
-
-// 	port := os.Getenv("PORT")
-// 	if port == "" {
-// 		port = "8080"
-// 	}
-
-// 	log.Println("Server listening on PORT ", port)
-// 	log.Fatal(http.ListenAndServe(":"+port, router))
-// }
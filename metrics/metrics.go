@@ -0,0 +1,84 @@
+// Package metrics holds the Prometheus collectors shared by the router, the
+// middleware and the stores, and the registry served on /metrics.
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "vanmango"
+
+// Transaction outcomes counted by ObserveTransaction
+const (
+	TxCommit   = "commit"
+	TxRollback = "rollback"
+)
+
+// Registry every collector is registered with. A dedicated registry rather than
+// the global default keeps third party packages from adding metrics implicitly.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests handled, by route template, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Time taken to handle HTTP requests, by route template and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	dbTransactions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "transactions_total",
+		Help:      "Store transactions ended, by outcome (commit or rollback) and whether ending them failed.",
+	}, []string{"outcome", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		dbTransactions,
+	)
+}
+
+// ObserveTransaction counts a commit or rollback, err is the error returned by it
+func ObserveTransaction(outcome string, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	dbTransactions.WithLabelValues(outcome, result).Inc()
+}
+
+// RegisterDB exposes the connection pool statistics (sql.DBStats) of db.
+// Registering the same database again is a no-op.
+func RegisterDB(db *sql.DB, name string) error {
+	err := Registry.Register(collectors.NewDBStatsCollector(db, name))
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if errors.As(err, &alreadyRegistered) {
+		return nil
+	}
+	return err
+}
+
+// Handler serves the registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -3,63 +3,115 @@ package middleware
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"errors"
 	"net/http"
-	"os"
 	"strings"
+	"time"
 
-	"github.com/golang-jwt/jwt"
-	"github.com/joho/godotenv"
+	"github.com/harshitrajsinha/goserver-vanmango/config"
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+	"github.com/harshitrajsinha/goserver-vanmango/routes"
+	"github.com/harshitrajsinha/goserver-vanmango/service"
+	"github.com/harshitrajsinha/goserver-vanmango/signing"
 )
 
-type Claims struct {
-	Username string `json:"username"`
-	jwt.StandardClaims
+// APIKeyHeader carries an API key, the alternative to a Bearer token for machine clients
+const APIKeyHeader = "X-API-Key"
+
+// Checks whether an access token ID (jti) has been revoked
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// Looks up a live API key, returns service.ErrInvalidAPIKey for unknown, expired or revoked keys
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.APIKeyRecord, error)
 }
 
-func generateKey() []byte {
-	_ = godotenv.Load()
-	jwtKeyString := os.Getenv("JWT_KEY")
-	return []byte(jwtKeyString)
+func writeAuthError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(routes.Response{Code: status, Message: message})
 }
 
-func AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(struct {
-				Code    int    `json:"code"`
-				Message string `json:"message"`
-			}{
-				Code: http.StatusUnauthorized, Message: "Authorization header required",
-			})
-			log.Println("Authorization header required")
-			return
-		}
+// AuthMiddleware accepts requests carrying either a valid, unrevoked Bearer token
+// signed with one of the keys or a live API key, in the X-API-Key header or as the
+// Bearer token. The caller is put in the request context, see models.PrincipalFromContext.
+func AuthMiddleware(keys *signing.KeySet, auth config.AuthConfig, revocations RevocationChecker, apiKeys APIKeyAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			logger := logging.FromContext(ctx)
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return generateKey(), nil
-		})
+			authHeader := r.Header.Get("Authorization")
+			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+			apiKey := r.Header.Get(APIKeyHeader)
+			if apiKey == "" && strings.HasPrefix(tokenString, service.APIKeyPrefix) {
+				apiKey = tokenString
+			}
 
-		if err != nil || !token.Valid {
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(struct {
-				Code    int    `json:"code"`
-				Message string `json:"message"`
-			}{
-				Code: http.StatusUnauthorized, Message: "Invalid token",
-			})
-			log.Println("Invalid token")
-			return
-		}
-		ctx := context.WithValue(r.Context(), "username", claims.Username)
-		next.ServeHTTP(w, r.WithContext(ctx))
+			if authHeader == "" && apiKey == "" {
+				writeAuthError(w, http.StatusUnauthorized, "Authorization header required")
+				logger.Info("Authorization header required")
+				return
+			}
 
-	})
+			var principal *models.Principal
+			if apiKey != "" {
+				record, err := apiKeys.AuthenticateAPIKey(ctx, apiKey)
+				if errors.Is(err, service.ErrInvalidAPIKey) {
+					writeAuthError(w, http.StatusUnauthorized, "Invalid API key")
+					logger.Info("Invalid API key")
+					return
+				}
+				if err != nil {
+					writeAuthError(w, http.StatusInternalServerError, "Error occured while verifying API key")
+					logger.Error("Error checking API key", "error", err)
+					return
+				}
+				principal = &models.Principal{Kind: models.PrincipalAPIKey, ID: record.ID.String(), Name: record.Name, Scopes: record.Scopes}
+			} else {
+				claims, err := routes.ParseToken(keys, auth, tokenString)
+				if err != nil {
+					writeAuthError(w, http.StatusUnauthorized, "Invalid token")
+					logger.Info("Invalid token", "error", err)
+					return
+				}
+
+				// tokens revoked on logout stay denylisted until they expire
+				revoked, err := revocations.IsRevoked(ctx, claims.Id)
+				if err != nil {
+					writeAuthError(w, http.StatusInternalServerError, "Error occured while verifying token")
+					logger.Error("Error checking token revocation", "error", err)
+					return
+				}
+				if revoked {
+					writeAuthError(w, http.StatusUnauthorized, "Token has been revoked")
+					logger.Info("Token has been revoked", "token-id", claims.Id)
+					return
+				}
+				principal = &models.Principal{
+					Kind:           models.PrincipalUser,
+					ID:             claims.Subject,
+					Name:           claims.Username,
+					Role:           claims.Role,
+					TokenID:        claims.Id,
+					TokenExpiresAt: time.Unix(claims.ExpiresAt, 0),
+				}
+			}
+
+			ctx = withLogUser(ctx, logUser(principal))
+			ctx = models.WithPrincipal(ctx, principal)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
 
+// Users are logged by ID, API keys by ID behind an "api-key:" prefix
+func logUser(principal *models.Principal) string {
+	if principal.Kind == models.PrincipalAPIKey {
+		return string(models.PrincipalAPIKey) + ":" + principal.ID
+	}
+	return principal.ID
 }
@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
+)
+
+// Header carrying the request ID, taken from the caller when present and always echoed back
+const RequestIDHeader = "X-Request-ID"
+
+// Longest caller supplied request ID that is accepted, anything else is replaced
+const maxRequestIDLength = 128
+
+type requestLogKey struct{}
+
+// Details learned while the request travels down the chain and reported in the access log
+type requestLog struct {
+	route   string
+	user    string
+	traceID string
+}
+
+// Records the status code and body size written by the handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if s.status == 0 {
+		s.status = code
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(b)
+	s.bytes += n
+	return n, err
+}
+
+// Status the client gets for a response. A handler that did not complete panicked
+// and is answered with a 500 by Recover, or has its connection dropped.
+func responseStatus(recorder *statusRecorder, completed bool) int {
+	if !completed {
+		return http.StatusInternalServerError
+	}
+	if recorder.status == 0 {
+		return http.StatusOK
+	}
+	return recorder.status
+}
+
+// Lets http.ResponseController reach the underlying writer
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// RequestLogger assigns every request an ID, or keeps the one sent in X-Request-ID,
+// and stores a logger tagged with it in the context. One access log line with the
+// method, route, status, latency, user and trace ID is written when the request completes.
+// It wraps the whole router so unmatched routes are logged too.
+func RequestLogger(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestID := r.Header.Get(RequestIDHeader)
+			if !validRequestID(requestID) {
+				requestID = uuid.NewString()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			entry := &requestLog{}
+			requestLogger := logger.With("request-id", requestID)
+			ctx := context.WithValue(r.Context(), requestLogKey{}, entry)
+			ctx = logging.WithLogger(ctx, requestLogger)
+
+			recorder := &statusRecorder{ResponseWriter: w}
+
+			// written while an aborted response unwinds too
+			completed := false
+			defer func() {
+				status := responseStatus(recorder, completed)
+				level := slog.LevelInfo
+				if status >= http.StatusInternalServerError {
+					level = slog.LevelError
+				}
+
+				attrs := []slog.Attr{
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.Int("status", status),
+					slog.Int("bytes", recorder.bytes),
+					slog.Float64("latency-ms", float64(time.Since(start).Microseconds())/1000),
+				}
+				if entry.route != "" {
+					attrs = append(attrs, slog.String("route", entry.route))
+				}
+				if entry.user != "" {
+					attrs = append(attrs, slog.String("user", entry.user))
+				}
+				if entry.traceID != "" {
+					attrs = append(attrs, slog.String("trace-id", entry.traceID))
+				}
+				if !completed {
+					attrs = append(attrs, slog.Bool("aborted", true))
+				}
+				requestLogger.LogAttrs(ctx, level, "request completed", attrs...)
+			}()
+
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+			completed = true
+		})
+	}
+}
+
+// RouteLogger is registered with router.Use. It runs once mux has matched the
+// request and adds the route template (e.g. /api/v1/van/{id}) to the request logger.
+func RouteLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := mux.CurrentRoute(r)
+		if route == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if entry, ok := r.Context().Value(requestLogKey{}).(*requestLog); ok {
+			entry.route = template
+		}
+		next.ServeHTTP(w, r.WithContext(logging.With(r.Context(), "route", template)))
+	})
+}
+
+// Record the authenticated user for the access log and tag later logs with it
+func withLogUser(ctx context.Context, userID string) context.Context {
+	if entry, ok := ctx.Value(requestLogKey{}).(*requestLog); ok {
+		entry.user = userID
+	}
+	return logging.With(ctx, "user", userID)
+}
+
+// Record the trace for the access log, written outside the traced scope, and tag later logs with it
+func withLogTrace(ctx context.Context, traceID string) context.Context {
+	if entry, ok := ctx.Value(requestLogKey{}).(*requestLog); ok {
+		entry.traceID = traceID
+	}
+	return logging.With(ctx, "trace-id", traceID)
+}
+
+// Accept caller IDs made of visible ASCII only, so they cannot break log lines or headers
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/harshitrajsinha/goserver-vanmango/metrics"
+)
+
+// Metrics is registered with router.Use and counts requests and their latency per
+// route template, so /api/v1/van/{id} is one series however many vans there are.
+// Paths that match no route never reach it and are not counted. A handler that
+// panics is counted as the 500 Recover answers with.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := mux.CurrentRoute(r)
+		if route == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+
+		// recorded while a panic unwinds too, Recover sits outside the router
+		completed := false
+		defer func() {
+			status := responseStatus(recorder, completed)
+			metrics.HTTPRequests.WithLabelValues(template, r.Method, strconv.Itoa(status)).Inc()
+			metrics.HTTPDuration.WithLabelValues(template, r.Method).Observe(time.Since(start).Seconds())
+		}()
+
+		next.ServeHTTP(recorder, r)
+		completed = true
+	})
+}
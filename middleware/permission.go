@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+	"github.com/harshitrajsinha/goserver-vanmango/routes"
+)
+
+// RequirePermission only lets the request through if the principal set by
+// AuthMiddleware holds the permission, through a user's role or a key's scopes
+func RequirePermission(permission models.Permission, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal := models.PrincipalFromContext(r.Context())
+		if !principal.Can(permission) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(routes.Response{Code: http.StatusForbidden, Message: "Insufficient permissions for this operation"})
+			logging.FromContext(r.Context()).Info("Permission denied", "permission", permission)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireUser only lets signed in users through, for routes such as logout that
+// act on the user's own account or token and mean nothing to an API key
+func RequireUser(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal := models.PrincipalFromContext(r.Context())
+		if principal == nil || principal.Kind != models.PrincipalUser {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(routes.Response{Code: http.StatusForbidden, Message: "Only available to signed in users"})
+			logging.FromContext(r.Context()).Info("User session required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+	"github.com/harshitrajsinha/goserver-vanmango/ratelimit"
+	"github.com/harshitrajsinha/goserver-vanmango/routes"
+)
+
+// RateLimit takes a request from the caller's bucket and answers 429 once it is
+// empty. Callers are told apart by their API key or user when AuthMiddleware ran
+// before it, by client address otherwise, and every caller gets one bucket per
+// scope. Responses carry RateLimit-Policy, RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset. A zero limit is a no-op.
+func RateLimit(limiter ratelimit.Limiter, scope string, limit ratelimit.Limit) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limit.Requests <= 0 {
+			return next
+		}
+		policy := strconv.Itoa(limit.Requests) + ";w=" + strconv.Itoa(int(limit.Period.Seconds()))
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			logger := logging.FromContext(ctx)
+
+			caller := "ip:" + routes.ClientIP(r)
+			if principal := models.PrincipalFromContext(ctx); principal != nil {
+				caller = string(principal.Kind) + ":" + principal.ID
+			}
+
+			// a failing shared backend must not take the API down with it
+			result, err := limiter.Allow(ctx, scope+":"+caller, limit)
+			if err != nil {
+				logger.Error("Rate limiter failed, request let through", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Policy", policy)
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("RateLimit-Reset", ceilSeconds(result.Reset))
+			if !result.Allowed {
+				w.Header().Set("Retry-After", ceilSeconds(result.RetryAfter))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusTooManyRequests)
+				json.NewEncoder(w).Encode(routes.Response{Code: http.StatusTooManyRequests, Message: "Rate limit exceeded, try again later"})
+				logger.Info("Rate limit exceeded", "scope", scope, "caller", caller)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RealIP sets r.RemoteAddr to the client address from X-Forwarded-For when the server
+// runs behind a trusted proxy such as Vercel's edge, so lockouts and limits apply to
+// the client and not to the proxy. The last entry is used, it is the one added by the
+// proxy itself, earlier entries come from the client and can be forged.
+func RealIP(trustProxy bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !trustProxy {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			forwarded := r.Header.Values("X-Forwarded-For")
+			if len(forwarded) > 0 {
+				hops := strings.Split(forwarded[len(forwarded)-1], ",")
+				if ip := net.ParseIP(strings.TrimSpace(hops[len(hops)-1])); ip != nil {
+					r.RemoteAddr = net.JoinHostPort(ip.String(), "0")
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
+	"github.com/harshitrajsinha/goserver-vanmango/routes"
+)
+
+// Recover turns a panic in any handler into a logged error. The stack is logged
+// with the request ID and a JSON 500 is sent. If the handler had already started
+// writing its response the connection is dropped instead, so the client sees a
+// failed request rather than a truncated one with JSON appended to it.
+// It wraps the router inside RequestLogger so the access log reports the 500.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w}
+
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// the server aborts the response on purpose with this value, let it through
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			logging.FromContext(r.Context()).Error("Recovered from panic",
+				"panic", fmt.Sprint(recovered),
+				"stack", string(debug.Stack()),
+			)
+			if recorder.status != 0 {
+				panic(http.ErrAbortHandler)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while processing request"})
+		}()
+
+		next.ServeHTTP(recorder, r)
+	})
+}
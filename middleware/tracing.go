@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/harshitrajsinha/goserver-vanmango/tracing"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing is registered with router.Use. It continues the trace sent in the
+// traceparent header, or starts a new one, and records a server span named after
+// the route template. The trace ID is added to the request logger and the access log. A handler that
+// panics leaves the span marked as a failed 500.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		ctx := tracing.Extract(r.Context(), r.Header)
+		ctx, span := tracing.Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		if spanContext := span.SpanContext(); spanContext.HasTraceID() {
+			ctx = withLogTrace(ctx, spanContext.TraceID().String())
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w}
+
+		// runs before span.End, also while a panic unwinds towards Recover
+		completed := false
+		defer func() {
+			status := responseStatus(recorder, completed)
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if !completed {
+				span.SetStatus(codes.Error, "handler panicked")
+			} else if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+		}()
+
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+		completed = true
+	})
+}
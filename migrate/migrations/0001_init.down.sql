@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS van_set_timestamp ON van;
+DROP TRIGGER IF EXISTS engine_set_timestamp ON engine;
+DROP FUNCTION IF EXISTS update_updated_at_column();
+
+DROP TABLE IF EXISTS van;
+DROP TABLE IF EXISTS engine;
+
+DROP TYPE IF EXISTS fuel_type;
+DROP TYPE IF EXISTS engine_material;
+DROP TYPE IF EXISTS category;
@@ -0,0 +1,70 @@
+-- Initial schema. Statements are idempotent so databases that were created by
+-- the old schema.sql bootstrap can adopt the migration history in place.
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'category') THEN
+        CREATE TYPE category AS ENUM ('simple', 'rugged', 'luxury');
+    END IF;
+END $$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'engine_material') THEN
+        CREATE TYPE engine_material AS ENUM ('aluminium', 'iron');
+    END IF;
+END $$;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'fuel_type') THEN
+        CREATE TYPE fuel_type AS ENUM ('petrol', 'diesel', 'gasoline');
+    END IF;
+END $$;
+
+-- Create table engine
+CREATE TABLE IF NOT EXISTS engine (
+    id UUID DEFAULT gen_random_uuid() UNIQUE PRIMARY KEY,
+    displacement_in_cc INT NOT NULL,
+    no_of_cylinders INT NOT NULL,
+    material  engine_material,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create table van
+CREATE TABLE IF NOT EXISTS van (
+    van_id UUID DEFAULT gen_random_uuid() UNIQUE PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    brand VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL,
+    category category NOT NULL,
+    fuel_type fuel_type NOT NULL,
+    engine_id UUID NOT NULL,
+    price INT NOT NULL,
+    image_url TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_engine_id FOREIGN KEY (engine_id) REFERENCES engine(id) ON DELETE CASCADE
+);
+
+-- Trigger function for updating updated_at
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS engine_set_timestamp ON engine;
+CREATE TRIGGER engine_set_timestamp
+BEFORE UPDATE ON engine
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS van_set_timestamp ON van;
+CREATE TRIGGER van_set_timestamp
+BEFORE UPDATE ON van
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
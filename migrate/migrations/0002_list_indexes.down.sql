@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_engine_displacement;
+DROP INDEX IF EXISTS idx_engine_created_at;
+DROP INDEX IF EXISTS idx_van_engine_id;
+DROP INDEX IF EXISTS idx_van_name;
+DROP INDEX IF EXISTS idx_van_price;
+DROP INDEX IF EXISTS idx_van_created_at;
@@ -0,0 +1,7 @@
+-- Indexes backing sorted, keyset-paginated and filtered list queries
+CREATE INDEX IF NOT EXISTS idx_van_created_at ON van (created_at, van_id);
+CREATE INDEX IF NOT EXISTS idx_van_price ON van (price, van_id);
+CREATE INDEX IF NOT EXISTS idx_van_name ON van (name, van_id);
+CREATE INDEX IF NOT EXISTS idx_van_engine_id ON van (engine_id);
+CREATE INDEX IF NOT EXISTS idx_engine_created_at ON engine (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_engine_displacement ON engine (displacement_in_cc, id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_van_search_vector;
+ALTER TABLE van DROP COLUMN IF EXISTS search_vector;
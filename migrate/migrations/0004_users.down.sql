@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS users_set_timestamp ON users;
+DROP TABLE IF EXISTS users;
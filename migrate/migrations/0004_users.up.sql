@@ -0,0 +1,17 @@
+-- User accounts, passwords are stored as bcrypt hashes
+CREATE TABLE IF NOT EXISTS users (
+    id UUID DEFAULT gen_random_uuid() UNIQUE PRIMARY KEY,
+    username VARCHAR(64) NOT NULL,
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Usernames are unique regardless of case
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (LOWER(username));
+
+DROP TRIGGER IF EXISTS users_set_timestamp ON users;
+CREATE TRIGGER users_set_timestamp
+BEFORE UPDATE ON users
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,15 @@
+-- API keys for machine clients. Only a SHA-256 hash of each key is stored, the
+-- prefix is kept in clear so admins can tell keys apart when listing them.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID DEFAULT gen_random_uuid() UNIQUE PRIMARY KEY,
+    name TEXT NOT NULL,
+    key_prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    created_by UUID,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_api_key_created_by FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
@@ -0,0 +1,13 @@
+-- Audit trail of login attempts. Failed attempts keep the username as typed,
+-- user_id is only set when the login succeeded.
+CREATE TABLE IF NOT EXISTS login_events (
+    id UUID DEFAULT gen_random_uuid() UNIQUE PRIMARY KEY,
+    username TEXT NOT NULL,
+    user_id UUID,
+    ip TEXT NOT NULL,
+    outcome TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_login_event_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_events_username_created_at ON login_events (LOWER(username), created_at);
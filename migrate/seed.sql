@@ -0,0 +1,22 @@
+-- Sample catalogue data. Applied explicitly via `vanmango seed`, never on startup.
+-- Rows carry fixed IDs so re-running the seed leaves existing data untouched.
+
+-- Insert data into the engine table (inserting data with ID to map with van's engine id)
+INSERT INTO engine (id, displacement_in_cc, no_of_cylinders, material)
+VALUES
+    ('e1f86b1a-0873-4c19-bae2-fc60329d0140', 2000, 4, 'aluminium'),
+    ('f4a9c66b-8e38-419b-93c4-215d5cefb318', 1600, 4, 'iron'),
+    ('cc2c2a7d-2e21-4f59-b7b8-bd9e5e4cf04c', 3000, 6, 'aluminium'),
+    ('9746be12-07b7-42a3-b8ab-7d1f209b63d7', 1800, 4, 'aluminium')
+ON CONFLICT (id) DO NOTHING;
+
+-- Insert data into the van table
+INSERT INTO van (van_id, name, brand, description, category, fuel_type, engine_id, price, image_url)
+VALUES
+    ('5d8e1dbf-202e-4b77-abc6-30f9942caf95', 'Modest Explorer', 'Honda', 'The Modest Explorer is a van designed to get you out of the house and into nature. This beauty is equipped with solar panels, a composting toilet, a water tank and kitchenette. The idea is that you can pack up your home and escape for a weekend or even longer!', 'simple', 'gasoline', 'e1f86b1a-0873-4c19-bae2-fc60329d0140', 5136, 'https://static.codia.ai/custom_image/2025-03-29/060113/modest-explorer-van.png'),
+    ('862a1b11-0b36-48ff-83f4-dc87cca76833', 'Beach Bum', 'Volkswagen', 'Beach Bum is a van inspired by surfers and travelers. It was created to be a portable home away from home, but with some cool features in it you wont find in an ordinary camper.','rugged','petrol', 'f4a9c66b-8e38-419b-93c4-215d5cefb318', 6849, 'https://static.codia.ai/custom_image/2025-03-29/060113/beach-bum-van.png'),
+    ('c7d75243-015b-46d6-860f-63ae1dcebb0e', 'Reliable Red', 'Toyota', 'Reliable Red is a van that was made for traveling. The inside is comfortable and cozy, with plenty of space to stretch out in. There is a small kitchen, so you can cook if you need to. Youll feel like home as soon as you step out of it.','luxury', 'diesel', 'cc2c2a7d-2e21-4f59-b7b8-bd9e5e4cf04c', 8561, 'https://static.codia.ai/custom_image/2025-03-29/060113/reliable-red-van.png'),
+    ('60865114-8578-446e-a86b-0dad10b4d636', 'Dreamfinder', 'Nissan', 'Dreamfinder is the perfect van to travel in and experience. With a ceiling height of 2.1m, you can stand up in this van and there is great head room. The floor is a beautiful glass-reinforced plastic (GRP) which is easy to clean and very hard wearing. A large rear window and large side windows make it really light inside and keep it well ventilated.','simple','gasoline', '9746be12-07b7-42a3-b8ab-7d1f209b63d7', 5564, 'https://static.codia.ai/custom_image/2025-03-29/060113/dreamfinder-van.png'),
+    ('bf018fb6-5d51-4d47-ab6e-286652230030', 'The Cruiser', 'Mercedes-Benz', 'The Cruiser is a van for those who love to travel in comfort and luxury. With its many windows, spacious interior and ample storage space, the Cruiser offers a beautiful view wherever you go.','luxury','diesel', 'cc2c2a7d-2e21-4f59-b7b8-bd9e5e4cf04c', 10273, 'https://static.codia.ai/custom_image/2025-03-29/060113/cruiser-van.png'),
+    ('3d00059d-68bb-47b6-809f-4c65db66232b', 'Green Wonder', 'Ford', 'With this van, you can take your travel life to the next level. The Green Wonder is a sustainable vehicle that is perfect for people who are looking for a stylish, eco-friendly mode of transport that can go anywhere.','rugged','gasoline', '9746be12-07b7-42a3-b8ab-7d1f209b63d7', 5992, 'https://static.codia.ai/custom_image/2025-03-29/060113/green-wonder-van.png')
+ON CONFLICT (van_id) DO NOTHING;
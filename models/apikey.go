@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Stored API key. Only a hash of the key is kept, the prefix identifies it in listings.
+type APIKeyRecord struct {
+	ID         uuid.UUID    `json:"id"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	Scopes     []Permission `json:"scopes"`
+	CreatedBy  *uuid.UUID   `json:"created-by,omitempty"`
+	ExpiresAt  *time.Time   `json:"expires-at,omitempty"`
+	LastUsedAt *time.Time   `json:"last-used-at,omitempty"`
+	RevokedAt  *time.Time   `json:"revoked-at,omitempty"`
+	CreatedAt  time.Time    `json:"created-at"`
+}
+
+// Newly minted API key, the plain key is only ever shown in this response
+type APIKeyCreated struct {
+	APIKeyRecord
+	Key string `json:"key"`
+}
+
+type APIKey struct {
+	Name      string       `json:"name"`
+	Scopes    []Permission `json:"scopes"`
+	ExpiresAt *time.Time   `json:"expires-at"`
+}
+
+// Permissions an API key can be granted. Managing users and keys stays with signed in admins.
+var APIKeyScopes = []Permission{PermEngineWrite, PermEngineDelete, PermVanWrite, PermVanDelete}
+
+func validateScope(scope Permission) error {
+	for _, allowed := range APIKeyScopes {
+		if scope == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("scope must be one of following - %q", APIKeyScopes)
+}
+
+func ValidateAPIKeyReq(apiKey APIKey) error {
+	if len(apiKey.Name) == 0 || len(apiKey.Name) > 64 {
+		return errors.New("name must be between 1 and 64 characters long")
+	}
+	if len(apiKey.Scopes) == 0 {
+		return errors.New("scopes must contain at least one scope")
+	}
+	for _, scope := range apiKey.Scopes {
+		if err := validateScope(scope); err != nil {
+			return err
+		}
+	}
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(time.Now()) {
+		return errors.New("expires-at must be in the future")
+	}
+	return nil
+}
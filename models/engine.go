@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"errors"
 	"strings"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Engine struct {
@@ -12,6 +15,16 @@ type Engine struct {
 	Material      string `json:"material"`
 }
 
+// Stored engine as returned by the store and service layers
+type EngineRecord struct {
+	ID            uuid.UUID `json:"id"`
+	Displacement  int64     `json:"displacement_in_cc"`
+	NoOfCylinders int       `json:"no_of_cylinders"`
+	Material      string    `json:"material"`
+	CreatedAt     time.Time `json:"created-at"`
+	UpdatedAt     time.Time `json:"updated-at"`
+}
+
 // 1500cc – 4000cc
 func validateDisplacement(displacement int64) error {
 	if displacement < 1500 || displacement > 4000 {
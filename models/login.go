@@ -1,6 +1,36 @@
 package models
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type Credentials struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 }
+
+// Failed logins recorded against a username or a client address
+type LoginAttempts struct {
+	Failures    int
+	LastFailure time.Time
+	LockedUntil time.Time
+}
+
+type LoginOutcome string
+
+const (
+	LoginSucceeded LoginOutcome = "success"
+	LoginFailed    LoginOutcome = "failure"
+)
+
+// Audit record of a login attempt, UserID is set for successful logins
+type LoginEvent struct {
+	ID        uuid.UUID    `json:"id"`
+	Username  string       `json:"username"`
+	UserID    *uuid.UUID   `json:"user-id,omitempty"`
+	IP        string       `json:"ip"`
+	Outcome   LoginOutcome `json:"outcome"`
+	CreatedAt time.Time    `json:"created-at"`
+}
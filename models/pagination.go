@@ -0,0 +1,193 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// Pagination metadata returned alongside list responses
+type PageInfo struct {
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset,omitempty"`
+	NextCursor string `json:"next-cursor,omitempty"`
+}
+
+// Cursor marks the last row of a page for keyset pagination.
+// It records the sort it was issued for so it cannot be replayed against a different ordering.
+type Cursor struct {
+	Sort  string    `json:"s"`
+	Order string    `json:"o"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// Encode cursor as an opaque URL-safe token
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode cursor token received from a client
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.New("cursor is invalid")
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == uuid.Nil {
+		return nil, errors.New("cursor is invalid")
+	}
+	return &cursor, nil
+}
+
+// Options shared by every list endpoint
+type ListOptions struct {
+	Sort   string
+	Order  string
+	Limit  int
+	Offset int
+	After  *Cursor
+}
+
+type VanFilter struct {
+	ListOptions
+	Category string
+	FuelType string
+	Brand    string
+	EngineID string
+	MinPrice int64
+	MaxPrice int64
+}
+
+type EngineFilter struct {
+	ListOptions
+	Material      string
+	NoOfCylinders int
+}
+
+var vanSortFields = []string{"created_at", "price", "name"}
+var engineSortFields = []string{"created_at", "displacement"}
+
+// Validate paging and sort options against the allowed sort fields, filling defaults
+func (o *ListOptions) validate(sortFields []string) error {
+	if o.Sort == "" {
+		o.Sort = sortFields[0]
+	}
+	validSort := false
+	for _, field := range sortFields {
+		if o.Sort == field {
+			validSort = true
+		}
+	}
+	if !validSort {
+		return errors.New("sort must be one of following - ['" + strings.Join(sortFields, "', '") + "']")
+	}
+
+	o.Order = strings.ToLower(o.Order)
+	if o.Order == "" {
+		o.Order = "asc"
+	}
+	if o.Order != "asc" && o.Order != "desc" {
+		return errors.New("order must be one of following - ['asc', 'desc']")
+	}
+
+	if o.Limit == 0 {
+		o.Limit = DefaultPageLimit
+	}
+	if o.Limit < 1 || o.Limit > MaxPageLimit {
+		return errors.New("limit must fall within the range of 1-100")
+	}
+	if o.Offset < 0 {
+		return errors.New("offset must not be negative")
+	}
+
+	if o.After != nil {
+		if o.Offset > 0 {
+			return errors.New("cursor and offset cannot be used together")
+		}
+		if o.After.Sort != o.Sort || o.After.Order != o.Order {
+			return errors.New("cursor does not match the requested sort order")
+		}
+		if !validCursorValue(o.Sort, o.After.Value) {
+			return errors.New("cursor is invalid")
+		}
+	}
+	return nil
+}
+
+// Cursors come back from clients, the value must fit the type of the sort column
+// before the store compares rows against it
+func validCursorValue(sort string, value string) bool {
+	switch sort {
+	case "created_at":
+		at, err := time.Parse(time.RFC3339Nano, value)
+		return err == nil && at.Year() >= 1
+	case "price", "displacement":
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	default:
+		return utf8.ValidString(value) && !strings.ContainsRune(value, 0)
+	}
+}
+
+func ValidateVanFilter(filter *VanFilter) error {
+	if err := filter.ListOptions.validate(vanSortFields); err != nil {
+		return err
+	}
+	if filter.Category != "" {
+		if err := validateCategory(filter.Category); err != nil {
+			return err
+		}
+	}
+	if filter.FuelType != "" {
+		if err := validateFuelType(filter.FuelType); err != nil {
+			return err
+		}
+	}
+	if filter.EngineID != "" {
+		engineID, err := uuid.Parse(filter.EngineID)
+		if err != nil {
+			return errors.New("engine-id is invalid")
+		}
+		if err := validateEngineID(engineID); err != nil {
+			return err
+		}
+	}
+	if filter.MinPrice < 0 || filter.MaxPrice < 0 {
+		return errors.New("price range must not be negative")
+	}
+	if filter.MaxPrice > 0 && filter.MinPrice > filter.MaxPrice {
+		return errors.New("min-price must not be greater than max-price")
+	}
+	return nil
+}
+
+func ValidateEngineFilter(filter *EngineFilter) error {
+	if err := filter.ListOptions.validate(engineSortFields); err != nil {
+		return err
+	}
+	if filter.Material != "" {
+		if err := validateMaterial(filter.Material); err != nil {
+			return err
+		}
+	}
+	if filter.NoOfCylinders != 0 {
+		if err := validateCylinderNo(filter.NoOfCylinders); err != nil {
+			return err
+		}
+	}
+	return nil
+}
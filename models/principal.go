@@ -0,0 +1,62 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+type PrincipalKind string
+
+const (
+	PrincipalUser   PrincipalKind = "user"
+	PrincipalAPIKey PrincipalKind = "api-key"
+)
+
+// Principal is the authenticated caller of a protected route, either a user
+// holding an access token or a client presenting an API key
+type Principal struct {
+	Kind PrincipalKind
+	// user ID or API key ID
+	ID string
+	// username, or the name the API key was minted with
+	Name string
+	// role of a user, API keys have none
+	Role Role
+	// permissions of an API key
+	Scopes []Permission
+	// ID and expiry of the access token, users only
+	TokenID        string
+	TokenExpiresAt time.Time
+}
+
+// Can reports whether the principal has been granted the permission,
+// through the user's role or the key's scopes
+func (p *Principal) Can(permission Permission) bool {
+	if p == nil {
+		return false
+	}
+	if p.Kind == PrincipalAPIKey {
+		for _, scope := range p.Scopes {
+			if scope == permission {
+				return true
+			}
+		}
+		return false
+	}
+	return p.Role.Can(permission)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the caller, AuthMiddleware sets it
+// for every protected route
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the caller stored by WithPrincipal, or nil on public
+// routes and for work that does not belong to a request
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
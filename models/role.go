@@ -0,0 +1,49 @@
+package models
+
+import (
+	"errors"
+)
+
+type Role string
+
+const (
+	RoleAdmin        Role = "admin"
+	RoleFleetManager Role = "fleet-manager"
+	RoleViewer       Role = "viewer"
+)
+
+type Permission string
+
+const (
+	PermEngineWrite  Permission = "engine:write"
+	PermEngineDelete Permission = "engine:delete"
+	PermVanWrite     Permission = "van:write"
+	PermVanDelete    Permission = "van:delete"
+	PermUserManage   Permission = "user:manage"
+	PermAPIKeyManage Permission = "api-key:manage"
+)
+
+// Permissions granted to each role. Deleting an engine cascades to its vans, so it is admin only.
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin:        {PermEngineWrite, PermEngineDelete, PermVanWrite, PermVanDelete, PermUserManage, PermAPIKeyManage},
+	RoleFleetManager: {PermEngineWrite, PermVanWrite, PermVanDelete},
+	RoleViewer:       {},
+}
+
+// Can reports whether the role has been granted the permission
+func (r Role) Can(permission Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// admin || fleet-manager || viewer
+func ValidateRole(role Role) error {
+	if _, exists := rolePermissions[role]; !exists {
+		return errors.New("role must be one of following - ['admin', 'fleet-manager', 'viewer']")
+	}
+	return nil
+}
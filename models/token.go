@@ -0,0 +1,29 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RefreshTokenRecord struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user-id"`
+	FamilyID  uuid.UUID  `json:"family-id"`
+	ExpiresAt time.Time  `json:"expires-at"`
+	UsedAt    *time.Time `json:"used-at,omitempty"`
+	RevokedAt *time.Time `json:"revoked-at,omitempty"`
+	CreatedAt time.Time  `json:"created-at"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh-token"`
+}
+
+func ValidateRefreshReq(request RefreshRequest) error {
+	if request.RefreshToken == "" {
+		return errors.New("refresh-token is required")
+	}
+	return nil
+}
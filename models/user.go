@@ -0,0 +1,73 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Stored user account. The password hash never leaves the server.
+type UserRecord struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"`
+	Role         Role      `json:"role"`
+	CreatedAt    time.Time `json:"created-at"`
+	UpdatedAt    time.Time `json:"updated-at"`
+}
+
+type PasswordChange struct {
+	CurrentPassword string `json:"current-password"`
+	NewPassword     string `json:"new-password"`
+}
+
+type RoleChange struct {
+	Role Role `json:"role"`
+}
+
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,64}$`)
+
+// 3-64 characters of letters, digits, '_', '.' or '-'
+func validateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return errors.New("username must be 3-64 characters of letters, digits, '_', '.' or '-'")
+	}
+	return nil
+}
+
+// 8-72 bytes, bcrypt ignores anything beyond 72 bytes
+func validatePassword(password string) error {
+	if len(password) < 8 || len(password) > 72 {
+		return errors.New("password must be between 8 and 72 characters long")
+	}
+	return nil
+}
+
+func ValidateRegistrationReq(credentials Credentials) error {
+	var err error
+
+	if err = validateUsername(credentials.Username); err != nil {
+		return err
+	}
+
+	if err = validatePassword(credentials.Password); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func ValidatePasswordChangeReq(request PasswordChange) error {
+	if request.CurrentPassword == "" {
+		return errors.New("current-password is required")
+	}
+	if err := validatePassword(request.NewPassword); err != nil {
+		return err
+	}
+	if request.CurrentPassword == request.NewPassword {
+		return errors.New("new-password must differ from current-password")
+	}
+	return nil
+}
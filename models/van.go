@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -19,6 +20,28 @@ type Van struct {
 	ImageURL    string    `json:"image-url"`
 }
 
+// Stored van as returned by the store and service layers
+type VanRecord struct {
+	ID          uuid.UUID `json:"van-id"`
+	Name        string    `json:"name"`
+	Brand       string    `json:"brand"`
+	Description string    `json:"description"`
+	Category    string    `json:"category"`
+	FuelType    string    `json:"fuel-type"`
+	EngineID    uuid.UUID `json:"engine-id"`
+	Price       int64     `json:"price"`
+	ImageURL    string    `json:"image-url"`
+	CreatedAt   time.Time `json:"created-at"`
+	UpdatedAt   time.Time `json:"updated-at"`
+}
+
+// Search hit carrying the relevance rank and a highlighted snippet
+type VanSearchResult struct {
+	VanRecord
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
 // Validate name
 func validateName(name string) error {
 	if name == "" {
@@ -237,3 +260,32 @@ func ValidateVanPatchReq(request []byte) error {
 
 	return nil
 }
+
+const maxSearchQueryLength = 200
+
+// Full-text search request over van name, brand and description
+type VanSearch struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+func ValidateVanSearch(search *VanSearch) error {
+	search.Query = strings.TrimSpace(search.Query)
+	if search.Query == "" {
+		return errors.New("search query 'q' is required")
+	}
+	if len(search.Query) > maxSearchQueryLength {
+		return errors.New("search query must not exceed 200 characters")
+	}
+	if search.Limit == 0 {
+		search.Limit = DefaultPageLimit
+	}
+	if search.Limit < 1 || search.Limit > MaxPageLimit {
+		return errors.New("limit must fall within the range of 1-100")
+	}
+	if search.Offset < 0 {
+		return errors.New("offset must not be negative")
+	}
+	return nil
+}
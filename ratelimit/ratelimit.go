@@ -0,0 +1,97 @@
+// Package ratelimit hands out requests from token buckets. The Limiter interface
+// lets a shared backend replace the in-process buckets when several instances
+// serve the API.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit allows Requests per Period on average, in bursts of up to Requests
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Outcome of taking a request from a bucket
+type Result struct {
+	Allowed bool
+	// Size of the bucket and requests left in it after this one
+	Limit     int
+	Remaining int
+	// Time until the bucket is full again
+	Reset time.Duration
+	// Time until the next request is allowed, zero when this one was
+	RetryAfter time.Duration
+}
+
+// Limiter takes one request from the bucket named by key
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// refill time of the limit the bucket was last used with
+	period time.Duration
+}
+
+// MemoryLimiter keeps the buckets in process memory, each instance counts on its own
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// Full buckets are dropped this often, they hold nothing a new bucket would not
+const sweepInterval = time.Minute
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{buckets: make(map[string]*bucket), lastSweep: time.Now()}
+}
+
+func (m *MemoryLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current := time.Now()
+	capacity := float64(limit.Requests)
+	perSecond := capacity / limit.Period.Seconds()
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: current}
+		m.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+current.Sub(b.updated).Seconds()*perSecond)
+	b.updated = current
+	b.period = limit.Period
+
+	result := Result{Limit: limit.Requests}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / perSecond)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((capacity - b.tokens) / perSecond)
+
+	if current.Sub(m.lastSweep) >= sweepInterval {
+		for existingKey, existing := range m.buckets {
+			if current.Sub(existing.updated) >= existing.period {
+				delete(m.buckets, existingKey)
+			}
+		}
+		m.lastSweep = current
+	}
+	return result, nil
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,29 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiterRefills(t *testing.T) {
+	limiter := NewMemoryLimiter()
+	limit := Limit{Requests: 2, Period: 100 * time.Millisecond}
+
+	for i := 0; i < 2; i++ {
+		if result, _ := limiter.Allow(t.Context(), "client", limit); !result.Allowed {
+			t.Fatalf("request %d should fit in the burst", i+1)
+		}
+	}
+	result, _ := limiter.Allow(t.Context(), "client", limit)
+	if result.Allowed || result.RetryAfter <= 0 || result.RetryAfter > 50*time.Millisecond {
+		t.Fatalf("empty bucket: got %+v, want a denial retrying within 50ms", result)
+	}
+	if result, _ := limiter.Allow(t.Context(), "other", limit); !result.Allowed {
+		t.Fatal("buckets must be separate per key")
+	}
+
+	time.Sleep(result.RetryAfter + 5*time.Millisecond)
+	if result, _ := limiter.Allow(t.Context(), "client", limit); !result.Allowed {
+		t.Fatal("a token should have been refilled")
+	}
+}
@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/harshitrajsinha/goserver-vanmango/store"
+)
+
+// Map store sentinel errors onto HTTP status codes, anything unrecognised is a 500
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, store.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, store.ErrForeignKey), errors.Is(err, store.ErrValidation):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// WriteError sends the error response for an error returned by the service layer.
+// Internal error details are never sent to the client.
+func WriteError(w http.ResponseWriter, err error) {
+	code := statusFromError(err)
+	message := "Error occured while processing request"
+	if code != http.StatusInternalServerError {
+		message = err.Error()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(Response{Code: code, Message: message})
+}
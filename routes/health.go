@@ -0,0 +1,94 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/harshitrajsinha/goserver-vanmango/buildinfo"
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
+)
+
+// Time a readiness probe waits for all dependency checks together
+const readinessTimeout = 2 * time.Second
+
+// Values of the status fields in probe responses
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+)
+
+// HealthCheck reports whether one dependency can serve traffic. Check returns
+// an optional detail, such as the schema version, shown in the probe response.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) (string, error)
+}
+
+type checkResult struct {
+	Status     string `json:"status"`
+	Detail     string `json:"detail,omitempty"`
+	DurationMS int64  `json:"duration-ms"`
+}
+
+type readinessResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks"`
+}
+
+type HealthHandler struct {
+	checks []HealthCheck
+}
+
+// Constructor method for health handler
+func NewHealthHandler(checks ...HealthCheck) *HealthHandler {
+	return &HealthHandler{checks: checks}
+}
+
+// Liveness answers as long as the process can serve requests, it never touches dependencies
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": statusOK})
+}
+
+// Readiness runs every dependency check and answers 503 if any of them fails
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+
+	logger := logging.FromContext(r.Context())
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	response := readinessResponse{Status: statusOK, Checks: make(map[string]checkResult, len(h.checks))}
+	for _, check := range h.checks {
+		start := time.Now()
+		detail, err := check.Check(ctx)
+		result := checkResult{Status: statusOK, Detail: detail, DurationMS: time.Since(start).Milliseconds()}
+		if err != nil {
+			// the cause is only logged, probes may be reachable from outside
+			result.Status = statusUnavailable
+			response.Status = statusUnavailable
+			logger.Error("Readiness check failed", "check", check.Name, "error", err)
+		}
+		response.Checks[check.Name] = result
+	}
+
+	code := http.StatusOK
+	if response.Status != statusOK {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Version reports the commit, build time and Go version of the running binary
+func (h *HealthHandler) Version(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(buildinfo.Get())
+}
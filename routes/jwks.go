@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/harshitrajsinha/goserver-vanmango/signing"
+)
+
+// How long verifiers may cache the key set. Keys are published before they sign,
+// so the lead time of a rotation must be longer than this.
+const jwksMaxAge = "300"
+
+type JWKSHandler struct {
+	keys *signing.KeySet
+}
+
+// Constructor method for JWKS handler
+func NewJWKSHandler(keys *signing.KeySet) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// KeySet serves the public keys access tokens are verified with
+func (h *JWKSHandler) KeySet(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age="+jwksMaxAge)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.keys.JWKS())
+}
@@ -1,34 +1,61 @@
 package routes
 
 import (
-	"encoding/json"
-	"log"
-	"net/http"
-	"os"
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/harshitrajsinha/goserver-vanmango/config"
 	"github.com/harshitrajsinha/goserver-vanmango/models"
-	"github.com/joho/godotenv"
+	"github.com/harshitrajsinha/goserver-vanmango/signing"
 )
 
-func GenerateToken(username string) (string, error) {
+// Clock difference tolerated between the instance issuing a token and the one checking it
+const clockSkew = 30 * time.Second
 
-	expiration := time.Now().Add(30 * time.Minute) // Expiration set as 30 minute
+// Claims of an access token, issued by GenerateToken and checked by ParseToken.
+// The subject is the user ID, the jti lets the token be revoked on logout.
+type Claims struct {
+	Username string      `json:"username"`
+	Role     models.Role `json:"role"`
+	jwt.StandardClaims
+}
 
-	claims := &jwt.StandardClaims{
-		ExpiresAt: expiration.Unix(),
-		IssuedAt:  time.Now().Unix(),
-		Subject:   username,
+// Valid checks the time claims, the parser calls it once the signature is verified.
+// Unlike jwt.StandardClaims, exp and nbf must be present.
+func (c *Claims) Valid() error {
+	now := time.Now()
+	if !c.VerifyExpiresAt(now.Add(-clockSkew).Unix(), true) {
+		return errors.New("token is expired")
 	}
+	if !c.VerifyNotBefore(now.Add(clockSkew).Unix(), true) {
+		return errors.New("token is not valid yet")
+	}
+	return nil
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Load JWT key
-	_ = godotenv.Load()
-	jwtKeyString := os.Getenv("JWT_KEY")
+// GenerateToken issues an access token for the user, valid for the configured TTL
+// and signed with the current key of the set
+func GenerateToken(keys *signing.KeySet, auth config.AuthConfig, user *models.UserRecord) (string, error) {
+
+	now := time.Now()
+
+	claims := &Claims{
+		Username: user.Username,
+		Role:     user.Role,
+		StandardClaims: jwt.StandardClaims{
+			Audience:  auth.Audience,
+			ExpiresAt: now.Add(auth.AccessTokenTTL).Unix(),
+			Id:        uuid.NewString(),
+			IssuedAt:  now.Unix(),
+			Issuer:    auth.Issuer,
+			NotBefore: now.Unix(),
+			Subject:   user.ID.String(),
+		},
+	}
 
-	signedToken, err := token.SignedString([]byte(jwtKeyString))
+	signedToken, err := keys.Sign(claims)
 	if err != nil {
 		return "", err
 	}
@@ -36,66 +63,30 @@ func GenerateToken(username string) (string, error) {
 	return signedToken, nil
 }
 
-func LoginHandler(w http.ResponseWriter, r *http.Request) {
-	var credentials models.Credentials
-
-	if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(struct {
-			Code    int    `json:"code"`
-			Message string `json:"message"`
-		}{
-			Code: http.StatusBadRequest, Message: "Invalid Request body for authorization",
-		})
-		log.Println("Invalid Request body for authorization")
-		return
-	}
-
-	// Load username and password
-	_ = godotenv.Load()
-	validUsername := os.Getenv("AUTH_USER")
-	validPassword := os.Getenv("AUTH_PASS")
+// ParseToken verifies the signature of an access token and returns its claims if
+// they are current and name this issuer and audience. Whether the token has been
+// revoked is left to the caller.
+func ParseToken(keys *signing.KeySet, auth config.AuthConfig, tokenString string) (*Claims, error) {
 
-	valid := (credentials.Username == validUsername && credentials.Password == validPassword)
+	// only the asymmetric algorithms are accepted, whatever the token header says
+	parser := &jwt.Parser{ValidMethods: signing.Algorithms()}
 
-	if !valid {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(struct {
-			Code    int    `json:"code"`
-			Message string `json:"message"`
-		}{
-			Code: http.StatusBadRequest, Message: "Incorrect username or password for authorization",
-		})
-		log.Println("Incorrect username or password for authorization")
-		return
+	claims := &Claims{}
+	if _, err := parser.ParseWithClaims(tokenString, claims, keys.Keyfunc); err != nil {
+		return nil, err
 	}
-
-	tokenString, err := GenerateToken(credentials.Username)
-
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(struct {
-			Code    int    `json:"code"`
-			Message string `json:"message"`
-		}{
-			Code: http.StatusInternalServerError, Message: "Failed to generate token for authorization",
-		})
-		log.Println("Failed to generate token for authorization")
-		return
+	if !claims.VerifyIssuer(auth.Issuer, true) {
+		return nil, errors.New("token is from another issuer")
+	}
+	if !claims.VerifyAudience(auth.Audience, true) {
+		return nil, errors.New("token is for another audience")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+	// the ID is looked up in the UUID typed denylist, anything else must not get that far
+	if _, err := uuid.Parse(claims.Id); err != nil {
+		return nil, errors.New("token ID is not a UUID")
 	}
-	response := make([]map[string]string, 0)
-	response = append(response, map[string]string{"token": tokenString})
-	w.WriteHeader(http.StatusCreated)
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(struct {
-		Code    int                 `json:"code"`
-		Message string              `json:"message"`
-		Data    []map[string]string `json:"data"`
-	}{
-		Code: http.StatusCreated, Message: "Authorization token generated successfully. Valid for next 30mins", Data: response,
-	})
-	log.Println("Authorization token generated successfully")
+	return claims, nil
 }
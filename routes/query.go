@@ -0,0 +1,113 @@
+package routes
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+)
+
+// Read paging and sort query parameters shared by every list endpoint
+func parseListOptions(query url.Values) (models.ListOptions, error) {
+	var opts models.ListOptions
+	var err error
+
+	opts.Sort = query.Get("sort")
+	opts.Order = query.Get("order")
+
+	if opts.Limit, err = parseIntParam(query, "limit"); err != nil {
+		return opts, err
+	}
+	if opts.Offset, err = parseIntParam(query, "offset"); err != nil {
+		return opts, err
+	}
+	if token := query.Get("cursor"); token != "" {
+		if opts.After, err = models.DecodeCursor(token); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+func parseIntParam(query url.Values, key string) (int, error) {
+	value := query.Get(key)
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number", key)
+	}
+	return number, nil
+}
+
+// Build a validated van filter from GET /api/v1/vans query parameters
+func ParseVanFilter(query url.Values) (*models.VanFilter, error) {
+	opts, err := parseListOptions(query)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &models.VanFilter{
+		ListOptions: opts,
+		Category:    query.Get("category"),
+		FuelType:    query.Get("fuel-type"),
+		Brand:       query.Get("brand"),
+		EngineID:    query.Get("engine-id"),
+	}
+
+	minPrice, err := parseIntParam(query, "min-price")
+	if err != nil {
+		return nil, err
+	}
+	maxPrice, err := parseIntParam(query, "max-price")
+	if err != nil {
+		return nil, err
+	}
+	filter.MinPrice, filter.MaxPrice = int64(minPrice), int64(maxPrice)
+
+	if err := models.ValidateVanFilter(filter); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// Build a validated engine filter from GET /api/v1/engines query parameters
+func ParseEngineFilter(query url.Values) (*models.EngineFilter, error) {
+	opts, err := parseListOptions(query)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &models.EngineFilter{
+		ListOptions: opts,
+		Material:    query.Get("material"),
+	}
+	if filter.NoOfCylinders, err = parseIntParam(query, "no-of-cylinders"); err != nil {
+		return nil, err
+	}
+
+	if err := models.ValidateEngineFilter(filter); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// Build a validated search request from GET /api/v1/vans/search query parameters
+func ParseVanSearch(query url.Values) (*models.VanSearch, error) {
+	search := &models.VanSearch{Query: query.Get("q")}
+
+	var err error
+	if search.Limit, err = parseIntParam(query, "limit"); err != nil {
+		return nil, err
+	}
+	if search.Offset, err = parseIntParam(query, "offset"); err != nil {
+		return nil, err
+	}
+
+	if err := models.ValidateVanSearch(search); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
@@ -2,13 +2,18 @@ package routes
 
 import (
 	"encoding/json"
+	"net"
+	"net/http"
 	"strings"
+
+	"github.com/harshitrajsinha/goserver-vanmango/models"
 )
 
 type Response struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
+	Code    int              `json:"code"`
+	Message string           `json:"message,omitempty"`
+	Data    interface{}      `json:"data,omitempty"`
+	Meta    *models.PageInfo `json:"meta,omitempty"`
 }
 
 func VerifyEngineRequestBody(body []byte, checkType int) bool {
@@ -118,3 +123,12 @@ func CheckAPIVersion(urlPath string) bool {
 		return true
 	}
 }
+
+// ClientIP returns the address of the caller without the port, see middleware.RealIP
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,109 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+	"github.com/harshitrajsinha/goserver-vanmango/routes"
+	"github.com/harshitrajsinha/goserver-vanmango/service"
+)
+
+type APIKeyHandler struct {
+	service service.APIKeyServiceInterface
+}
+
+func NewAPIKeyHandler(service service.APIKeyServiceInterface) *APIKeyHandler {
+	return &APIKeyHandler{
+		service: service,
+	}
+}
+
+func (a *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+	logger := logging.FromContext(ctx)
+
+	// the key is minted on behalf of the signed in admin, set by AuthMiddleware
+	principal := models.PrincipalFromContext(ctx)
+
+	var apiKeyReq models.APIKey
+	if err := json.NewDecoder(r.Body).Decode(&apiKeyReq); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Value type is incorrect"})
+		logger.Info("Invalid request", "error", err)
+		return
+	}
+
+	// validate request body
+	if err := models.ValidateAPIKeyReq(apiKeyReq); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: err.Error()})
+		logger.Info("Invalid request", "error", err)
+		return
+	}
+
+	createdKey, err := a.service.CreateAPIKey(ctx, &apiKeyReq, principal.ID)
+	if err != nil {
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(routes.Response{Code: http.StatusCreated, Message: "API key created successfully. Store the key now, it cannot be shown again", Data: []models.APIKeyCreated{*createdKey}})
+	logger.Info("API key created", "api-key-id", createdKey.ID, "scopes", createdKey.Scopes)
+}
+
+func (a *APIKeyHandler) GetAllAPIKey(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+	logger := logging.FromContext(ctx)
+
+	apiKeys, err := a.service.GetAllAPIKey(ctx)
+	if err != nil {
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(routes.Response{Code: http.StatusOK, Data: apiKeys})
+	logger.Debug("All API keys populated successfully")
+}
+
+func (a *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+	logger := logging.FromContext(ctx)
+
+	// Get API key id
+	params := mux.Vars(r)
+	id := params["id"]
+
+	// Check if id is valid uuid
+	result, _ := uuid.Parse(id)
+	if result.Version() != 4 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Invalid API key ID"})
+		logger.Info("Invalid API key ID")
+		return
+	}
+
+	if _, err := a.service.RevokeAPIKey(ctx, id); err != nil {
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("API key revoked", "api-key-id", id)
+}
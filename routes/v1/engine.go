@@ -3,13 +3,12 @@ package routes
 import (
 	"encoding/json"
 	"io"
-	"log"
 	"net/http"
-	"runtime/debug"
 	"strings"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
 	"github.com/harshitrajsinha/goserver-vanmango/models"
 	"github.com/harshitrajsinha/goserver-vanmango/routes"
 	"github.com/harshitrajsinha/goserver-vanmango/service"
@@ -30,15 +29,8 @@ func NewEngineHandler(service service.EngineServiceInterface) *EngineHandler {
 
 func (e *EngineHandler) GetEngineByID(w http.ResponseWriter, r *http.Request) {
 
-	// panic recovery
-	defer func() {
-		if r := recover(); r != nil {
-			log.Println("Error occured: ", r)
-			debug.PrintStack()
-		}
-	}()
-
 	ctx := r.Context()
+	logger := logging.FromContext(ctx)
 
 	// Get engine id
 	vars := mux.Vars(r)
@@ -50,69 +42,60 @@ func (e *EngineHandler) GetEngineByID(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Invalid engine ID"})
-		log.Println("Invalid Engine ID")
+		logger.Info("Invalid Engine ID")
 		return
 	}
 
 	// Get data from service layer
 	resp, err := e.service.GetEngineByID(ctx, id)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while reading data"})
-		panic(err)
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
+		return
 	}
 
 	// Send response
-	var respData []interface{}
-	respData = append(respData, resp) // enclose data in an array
+	respData := []models.EngineRecord{*resp} // enclose data in an array
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(routes.Response{Code: http.StatusOK, Data: respData})
-	log.Println("Engine data populated successfully based on ID")
+	logger.Debug("Engine data populated successfully based on ID")
 }
 
 func (e *EngineHandler) GetAllEngine(w http.ResponseWriter, r *http.Request) {
 
-	// panic recovery
-	defer func() {
-		var r interface{}
-		if r = recover(); r != nil {
-			log.Println("Error occured: ", r)
-			debug.PrintStack()
-		}
-	}()
-
 	ctx := r.Context()
+	logger := logging.FromContext(ctx)
 
-	// Get data from service layer
-	resp, err := e.service.GetAllEngine(ctx)
+	// Read pagination, sort and filter parameters
+	filter, err := routes.ParseEngineFilter(r.URL.Query())
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while reading data"})
-		panic(err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: err.Error()})
+		logger.Info("Invalid request", "error", err)
+		return
+	}
+
+	// Get data from service layer
+	resp, pageInfo, err := e.service.GetAllEngine(ctx, filter)
+	if err != nil {
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
+		return
 	}
 
 	// Send response
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(routes.Response{Code: http.StatusOK, Data: resp})
-	log.Println("All engine data populated successfully")
+	json.NewEncoder(w).Encode(routes.Response{Code: http.StatusOK, Data: resp, Meta: pageInfo})
+	logger.Debug("All engine data populated successfully")
 }
 
 func (e *EngineHandler) CreateEngine(w http.ResponseWriter, r *http.Request) {
 
-	// panic recovery
-	defer func() {
-		var r interface{}
-		if r = recover(); r != nil {
-			log.Println("Error occured: ", r)
-			debug.PrintStack()
-		}
-	}()
-
 	ctx := r.Context()
+	logger := logging.FromContext(ctx)
 
 	// Read request body
 	body, err := io.ReadAll(r.Body)
@@ -120,7 +103,8 @@ func (e *EngineHandler) CreateEngine(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while reading data"})
-		panic(err)
+		logger.Error("Error occured while reading data", "error", err)
+		return
 	}
 	defer r.Body.Close()
 
@@ -130,7 +114,7 @@ func (e *EngineHandler) CreateEngine(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Value type is incorrect"})
-		log.Println(err)
+		logger.Info("Invalid request", "error", err)
 		return
 	}
 
@@ -140,7 +124,7 @@ func (e *EngineHandler) CreateEngine(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Missing required fields"})
-		log.Println("Missing required fields")
+		logger.Info("Missing required fields")
 		return
 	}
 
@@ -149,46 +133,30 @@ func (e *EngineHandler) CreateEngine(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: err.Error()})
-		log.Println(err)
+		logger.Info("Invalid request", "error", err)
 		return
 	}
 
 	// Pass data to service layer to create engine
 	createdEngine, err := e.service.CreateEngine(ctx, &engineReq)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while reading data"})
-		panic(err)
-	}
-
-	// send response
-	if createdEngine > 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusCreated, Message: "engine data inserted into DB successfully!"})
-		log.Println("engine data inserted into DB successfully!")
-	} else {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "No rows inserted - Possibly data already exists"})
-		log.Println("No rows inserted - Possibly data already exists")
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
+		return
 	}
 
+	// send response, Location points at the new engine
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/v1/engine/"+createdEngine.ID.String())
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(routes.Response{Code: http.StatusCreated, Message: "engine data inserted into DB successfully!", Data: []models.EngineRecord{*createdEngine}})
+	logger.Debug("engine data inserted into DB successfully!")
 }
 
 func (e *EngineHandler) UpdateEngine(w http.ResponseWriter, r *http.Request) {
 
-	// panic recovery
-	defer func() {
-		var r interface{}
-		if r = recover(); r != nil {
-			log.Println("Error occured: ", r)
-			debug.PrintStack()
-		}
-	}()
-
 	ctx := r.Context()
+	logger := logging.FromContext(ctx)
 
 	// Get id
 	params := mux.Vars(r)
@@ -200,7 +168,7 @@ func (e *EngineHandler) UpdateEngine(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Invalid engine ID"})
-		log.Println("Invalid Engine ID")
+		logger.Info("Invalid Engine ID")
 		return
 	}
 	defer r.Body.Close()
@@ -211,7 +179,8 @@ func (e *EngineHandler) UpdateEngine(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while reading data"})
-		panic(err)
+		logger.Error("Error occured while reading data", "error", err)
+		return
 	}
 
 	var engineReq models.Engine
@@ -220,7 +189,7 @@ func (e *EngineHandler) UpdateEngine(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Value type is incorrect"})
-		log.Println(err)
+		logger.Info("Invalid request", "error", err)
 		return
 	}
 
@@ -238,45 +207,28 @@ func (e *EngineHandler) UpdateEngine(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: err.Error()})
-		log.Println(err)
+		logger.Info("Invalid request", "error", err)
 		return
 	}
 
 	// Pass data to service layer to update engine
-	updatedEngine, err := e.service.UpdateEngine(ctx, id, &engineReq)
+	_, err = e.service.UpdateEngine(ctx, id, &engineReq)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while reading data"})
-		panic(err)
-	}
-
-	if updatedEngine > 0 {
-		// data is updated successfully
-		log.Println("Engine data updated successfully!")
-		// Get the updated result
-		e.GetEngineByID(w, r)
-	} else {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "No data present for provided Engine ID or data already exists"})
-		log.Println("value of updatedEngine is ", updatedEngine)
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
 		return
 	}
+
+	// data is updated successfully
+	logger.Debug("Engine data updated successfully!")
+	// Get the updated result
+	e.GetEngineByID(w, r)
 }
 
 func (e *EngineHandler) UpdateEnginePartial(w http.ResponseWriter, r *http.Request) {
 
-	// panic recovery
-	defer func() {
-		var r interface{}
-		if r = recover(); r != nil {
-			log.Println("Error occured: ", r)
-			debug.PrintStack()
-		}
-	}()
-
 	ctx := r.Context()
+	logger := logging.FromContext(ctx)
 
 	// Get id
 	params := mux.Vars(r)
@@ -288,7 +240,7 @@ func (e *EngineHandler) UpdateEnginePartial(w http.ResponseWriter, r *http.Reque
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Invalid engine ID"})
-		log.Println("Invalid Engine ID")
+		logger.Info("Invalid Engine ID")
 		return
 	}
 	defer r.Body.Close()
@@ -299,7 +251,8 @@ func (e *EngineHandler) UpdateEnginePartial(w http.ResponseWriter, r *http.Reque
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while reading data"})
-		panic(err)
+		logger.Error("Error occured while reading data", "error", err)
+		return
 	}
 
 	var engineReq models.Engine
@@ -308,7 +261,7 @@ func (e *EngineHandler) UpdateEnginePartial(w http.ResponseWriter, r *http.Reque
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Value type is incorrect"})
-		log.Println(err)
+		logger.Info("Invalid request", "error", err)
 		return
 	}
 
@@ -326,45 +279,28 @@ func (e *EngineHandler) UpdateEnginePartial(w http.ResponseWriter, r *http.Reque
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: err.Error()})
-		log.Println(err)
+		logger.Info("Invalid request", "error", err)
 		return
 	}
 
 	// Pass data to service layer to update engine
-	updatedEngine, err := e.service.UpdateEngine(ctx, id, &engineReq)
+	_, err = e.service.UpdateEngine(ctx, id, &engineReq)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while reading data"})
-		panic(err)
-	}
-
-	if updatedEngine > 0 {
-		// data is updated successfully
-		log.Println("Engine data updated successfully!")
-		// Get the updated result
-		e.GetEngineByID(w, r)
-	} else {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "No data present for provided Engine ID or data already exists"})
-		log.Println("value of updatedEngine is ", updatedEngine)
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
 		return
 	}
+
+	// data is updated successfully
+	logger.Debug("Engine data updated successfully!")
+	// Get the updated result
+	e.GetEngineByID(w, r)
 }
 
 func (e *EngineHandler) DeleteEngine(w http.ResponseWriter, r *http.Request) {
 
-	// panic recovery
-	defer func() {
-		var r interface{}
-		if r = recover(); r != nil {
-			log.Println("Error occured: ", r)
-			debug.PrintStack()
-		}
-	}()
-
 	ctx := r.Context()
+	logger := logging.FromContext(ctx)
 	params := mux.Vars(r)
 
 	// Get id
@@ -376,31 +312,20 @@ func (e *EngineHandler) DeleteEngine(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Invalid engine ID"})
-		log.Println("Invalid Engine ID")
+		logger.Info("Invalid Engine ID")
 		return
 	}
 
 	// Pass data to service layer to delete engine
 	deletedEngine, err := e.service.DeleteEngine(ctx, id)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while deleting data"})
-		panic(err)
-	}
-
-	if deletedEngine > 0 {
-		// data is deleted successfully
-		w.WriteHeader(http.StatusNoContent)
-		w.Header().Set("Content-Type", "application/json")
-		log.Println("value of deletedEngine is ", deletedEngine)
-		return
-	} else {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "No data present for provided Engine ID or data already deleted"})
-		log.Println("value of deletedEngine is ", deletedEngine)
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
 		return
 	}
 
+	// data is deleted successfully
+	w.WriteHeader(http.StatusNoContent)
+	logger.Debug("Engine deleted", "rows", deletedEngine)
+
 }
@@ -0,0 +1,345 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/harshitrajsinha/goserver-vanmango/config"
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+	"github.com/harshitrajsinha/goserver-vanmango/routes"
+	"github.com/harshitrajsinha/goserver-vanmango/service"
+	"github.com/harshitrajsinha/goserver-vanmango/signing"
+)
+
+type UserHandler struct {
+	service      service.UserServiceInterface
+	tokenService service.TokenServiceInterface
+	loginGuard   service.LoginGuardInterface
+	keys         *signing.KeySet
+	auth         config.AuthConfig
+}
+
+func NewUserHandler(service service.UserServiceInterface, tokenService service.TokenServiceInterface, loginGuard service.LoginGuardInterface, keys *signing.KeySet, auth config.AuthConfig) *UserHandler {
+	return &UserHandler{
+		service:      service,
+		tokenService: tokenService,
+		loginGuard:   loginGuard,
+		keys:         keys,
+		auth:         auth,
+	}
+}
+
+func (u *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+	logger := logging.FromContext(ctx)
+
+	var credentials models.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Invalid Request body for registration"})
+		logger.Info("Invalid Request body for registration")
+		return
+	}
+
+	// validate request body
+	if err := models.ValidateRegistrationReq(credentials); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: err.Error()})
+		logger.Info("Invalid request", "error", err)
+		return
+	}
+
+	// Pass data to service layer to create user
+	user, err := u.service.Register(ctx, &credentials)
+	if err != nil {
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(routes.Response{Code: http.StatusCreated, Message: "User registered successfully", Data: []interface{}{user}})
+	logger.Debug("User registered successfully")
+}
+
+func (u *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+	logger := logging.FromContext(ctx)
+
+	var credentials models.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Invalid Request body for authorization"})
+		logger.Info("Invalid Request body for authorization")
+		return
+	}
+
+	// locked out usernames and addresses are refused before the password is checked
+	ip := routes.ClientIP(r)
+	var lockedErr *service.LoginLockedError
+	err := u.loginGuard.Check(ctx, credentials.Username, ip)
+	if errors.As(err, &lockedErr) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusTooManyRequests, Message: "Too many failed login attempts, try again later"})
+		return
+	}
+	if err != nil {
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
+		return
+	}
+
+	user, err := u.service.Authenticate(ctx, &credentials)
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		if err := u.loginGuard.RecordFailure(ctx, credentials.Username, ip); err != nil {
+			logger.Error("Recording failed login failed", "error", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Incorrect username or password for authorization"})
+		logger.Info("Incorrect username or password for authorization")
+		return
+	}
+	if err != nil {
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
+		return
+	}
+
+	if err := u.loginGuard.RecordSuccess(ctx, user, ip); err != nil {
+		logger.Error("Recording successful login failed", "error", err)
+	}
+
+	refreshToken, err := u.tokenService.IssueRefreshToken(ctx, user)
+	if err != nil {
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
+		return
+	}
+
+	u.writeTokens(ctx, w, user, refreshToken)
+}
+
+// Issue an access token and send it along with the refresh token
+func (u *UserHandler) writeTokens(ctx context.Context, w http.ResponseWriter, user *models.UserRecord, refreshToken string) {
+	logger := logging.FromContext(ctx)
+
+	tokenString, err := routes.GenerateToken(u.keys, u.auth, user)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Failed to generate token for authorization"})
+		logger.Error("Failed to generate token for authorization", "error", err)
+		return
+	}
+
+	response := make([]map[string]string, 0)
+	response = append(response, map[string]string{"token": tokenString, "refresh-token": refreshToken})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(routes.Response{Code: http.StatusCreated, Message: fmt.Sprintf("Authorization token generated successfully. Valid for next %s", u.auth.AccessTokenTTL), Data: response})
+	logger.Debug("Authorization token generated successfully")
+}
+
+func (u *UserHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+	logger := logging.FromContext(ctx)
+
+	var refreshReq models.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&refreshReq); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Value type is incorrect"})
+		logger.Info("Invalid request", "error", err)
+		return
+	}
+
+	// validate request body
+	if err := models.ValidateRefreshReq(refreshReq); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: err.Error()})
+		logger.Info("Invalid request", "error", err)
+		return
+	}
+
+	// refresh tokens are single use, a new one is issued on every refresh
+	user, refreshToken, err := u.tokenService.RotateRefreshToken(ctx, refreshReq.RefreshToken)
+	if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrRefreshTokenReused) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusUnauthorized, Message: err.Error()})
+		logger.Info("Invalid request", "error", err)
+		return
+	}
+	if err != nil {
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
+		return
+	}
+
+	u.writeTokens(ctx, w, user, refreshToken)
+}
+
+func (u *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+	logger := logging.FromContext(ctx)
+
+	// token ID and expiry of the access token, set by AuthMiddleware
+	principal := models.PrincipalFromContext(ctx)
+
+	// refresh token in the body is optional, when present its whole family is revoked too
+	var refreshReq models.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&refreshReq); err != nil && !errors.Is(err, io.EOF) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Value type is incorrect"})
+		logger.Info("Invalid request", "error", err)
+		return
+	}
+
+	if err := u.tokenService.RevokeAccessToken(ctx, principal.TokenID, principal.TokenExpiresAt); err != nil {
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
+		return
+	}
+
+	if refreshReq.RefreshToken != "" {
+		err := u.tokenService.RevokeRefreshToken(ctx, principal.ID, refreshReq.RefreshToken)
+		if err != nil && !errors.Is(err, service.ErrInvalidRefreshToken) {
+			routes.WriteError(w, err)
+			logger.Info("Request failed", "error", err)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	logger.Debug("User logged out successfully")
+}
+
+func (u *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+	logger := logging.FromContext(ctx)
+
+	// user ID is the token subject, set by AuthMiddleware
+	principal := models.PrincipalFromContext(ctx)
+
+	var passwordReq models.PasswordChange
+	if err := json.NewDecoder(r.Body).Decode(&passwordReq); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Value type is incorrect"})
+		logger.Info("Invalid request", "error", err)
+		return
+	}
+
+	// validate request body
+	if err := models.ValidatePasswordChangeReq(passwordReq); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: err.Error()})
+		logger.Info("Invalid request", "error", err)
+		return
+	}
+
+	err := u.service.ChangePassword(ctx, principal.ID, &passwordReq)
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Current password is incorrect"})
+		logger.Info("Current password is incorrect")
+		return
+	}
+	if err != nil {
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
+		return
+	}
+
+	// a stolen session must not outlive the password, the caller and every other session log in again
+	if err := u.tokenService.RevokeUserRefreshTokens(ctx, principal.ID); err != nil {
+		routes.WriteError(w, err)
+		logger.Error("Failed to revoke refresh tokens after password change", "error", err)
+		return
+	}
+	if err := u.tokenService.RevokeAccessToken(ctx, principal.TokenID, principal.TokenExpiresAt); err != nil {
+		routes.WriteError(w, err)
+		logger.Error("Failed to revoke access token after password change", "error", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(routes.Response{Code: http.StatusOK, Message: "Password changed successfully"})
+	logger.Debug("Password changed successfully")
+}
+
+func (u *UserHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+	logger := logging.FromContext(ctx)
+
+	// Get user id
+	params := mux.Vars(r)
+	id := params["id"]
+
+	// Check if id is valid uuid
+	result, _ := uuid.Parse(id)
+	if result.Version() != 4 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Invalid user ID"})
+		logger.Info("Invalid user ID")
+		return
+	}
+
+	var roleReq models.RoleChange
+	if err := json.NewDecoder(r.Body).Decode(&roleReq); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Value type is incorrect"})
+		logger.Info("Invalid request", "error", err)
+		return
+	}
+
+	// validate request body
+	if err := models.ValidateRole(roleReq.Role); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: err.Error()})
+		logger.Info("Invalid request", "error", err)
+		return
+	}
+
+	user, err := u.service.SetRole(ctx, id, roleReq.Role)
+	if err != nil {
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(routes.Response{Code: http.StatusOK, Message: "Role updated successfully. Takes effect on next login", Data: []interface{}{user}})
+	logger.Debug("User role updated successfully")
+}
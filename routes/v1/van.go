@@ -3,13 +3,12 @@ package routes
 import (
 	"encoding/json"
 	"io"
-	"log"
 	"net/http"
-	"runtime/debug"
 	"strings"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
 	"github.com/harshitrajsinha/goserver-vanmango/models"
 	"github.com/harshitrajsinha/goserver-vanmango/routes"
 	"github.com/harshitrajsinha/goserver-vanmango/service"
@@ -30,15 +29,8 @@ func NewVanHandler(service service.VanServiceInterface) *VanHandler {
 
 func (v *VanHandler) GetVanByID(w http.ResponseWriter, r *http.Request) {
 
-	// panic recovery
-	defer func() {
-		if r := recover(); r != nil {
-			log.Println("Error occured: ", r)
-			debug.PrintStack()
-		}
-	}()
-
 	ctx := r.Context()
+	logger := logging.FromContext(ctx)
 
 	// Get van id
 	vars := mux.Vars(r)
@@ -50,69 +42,90 @@ func (v *VanHandler) GetVanByID(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Invalid engine ID"})
-		log.Println("Invalid Van ID")
+		logger.Info("Invalid Van ID")
 		return
 	}
 
 	// Get data from service layer
 	resp, err := v.service.GetVanById(ctx, id)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while reading data"})
-		panic(err)
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
+		return
 	}
 
 	// Send response
-	var respData []interface{}
-	respData = append(respData, resp) // enclose data in an array
+	respData := []models.VanRecord{*resp} // enclose data in an array
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(routes.Response{Code: http.StatusOK, Data: respData})
-	log.Println("Van data populated successfully based on ID")
+	logger.Debug("Van data populated successfully based on ID")
 }
 
 func (v *VanHandler) GetAllVan(w http.ResponseWriter, r *http.Request) {
 
-	// panic recovery
-	defer func() {
-		var r interface{}
-		if r = recover(); r != nil {
-			log.Println("Error occured: ", r)
-			debug.PrintStack()
-		}
-	}()
-
 	ctx := r.Context()
+	logger := logging.FromContext(ctx)
+
+	// Read pagination, sort and filter parameters
+	filter, err := routes.ParseVanFilter(r.URL.Query())
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: err.Error()})
+		logger.Info("Invalid request", "error", err)
+		return
+	}
 
 	// Get data from service layer
-	resp, err := v.service.GetAllVan(ctx)
+	resp, pageInfo, err := v.service.GetAllVan(ctx, filter)
+	if err != nil {
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
+		return
+	}
+
+	// Send response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(routes.Response{Code: http.StatusOK, Data: resp, Meta: pageInfo})
+	logger.Debug("All van data populated successfully")
+}
+
+func (v *VanHandler) SearchVan(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+	logger := logging.FromContext(ctx)
+
+	// Read search text and paging parameters
+	search, err := routes.ParseVanSearch(r.URL.Query())
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while reading data"})
-		panic(err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: err.Error()})
+		logger.Info("Invalid request", "error", err)
+		return
+	}
+
+	// Get ranked results from service layer
+	resp, pageInfo, err := v.service.SearchVan(ctx, search)
+	if err != nil {
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
+		return
 	}
 
 	// Send response
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(routes.Response{Code: http.StatusOK, Data: resp})
-	log.Println("All van data populated successfully")
+	json.NewEncoder(w).Encode(routes.Response{Code: http.StatusOK, Data: resp, Meta: pageInfo})
+	logger.Debug("Van search results populated successfully")
 }
 
 func (v *VanHandler) CreateVan(w http.ResponseWriter, r *http.Request) {
 
-	// panic recovery
-	defer func() {
-		var r interface{}
-		if r = recover(); r != nil {
-			log.Println("Error occured: ", r)
-			debug.PrintStack()
-		}
-	}()
-
 	ctx := r.Context()
+	logger := logging.FromContext(ctx)
 
 	// Read request body
 	body, err := io.ReadAll(r.Body)
@@ -120,7 +133,8 @@ func (v *VanHandler) CreateVan(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while reading data"})
-		panic(err)
+		logger.Error("Error occured while reading data", "error", err)
+		return
 	}
 	defer r.Body.Close()
 
@@ -130,7 +144,7 @@ func (v *VanHandler) CreateVan(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Value type is incorrect"})
-		log.Println(err)
+		logger.Info("Invalid request", "error", err)
 		return
 	}
 
@@ -140,7 +154,7 @@ func (v *VanHandler) CreateVan(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Missing required fields"})
-		log.Println("Missing required fields")
+		logger.Info("Missing required fields")
 		return
 	}
 
@@ -149,45 +163,30 @@ func (v *VanHandler) CreateVan(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: err.Error()})
-		log.Println(err)
+		logger.Info("Invalid request", "error", err)
 		return
 	}
 
 	// Pass data to service layer to create van
 	createdVan, err := v.service.CreateVan(ctx, &vanReq)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while reading data"})
-		panic(err)
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
+		return
 	}
 
-	// send response
-	if createdVan > 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusCreated, Message: "van data inserted into DB successfully!"})
-		log.Println("van data inserted into DB successfully!")
-	} else {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "No rows inserted - Possibly data already exists"})
-		log.Println("No rows inserted - Possibly data already exists")
-	}
+	// send response, Location points at the new van
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/v1/van/"+createdVan.ID.String())
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(routes.Response{Code: http.StatusCreated, Message: "van data inserted into DB successfully!", Data: []models.VanRecord{*createdVan}})
+	logger.Debug("van data inserted into DB successfully!")
 }
 
 func (v *VanHandler) UpdateVan(w http.ResponseWriter, r *http.Request) {
 
-	// panic recovery
-	defer func() {
-		var r interface{}
-		if r = recover(); r != nil {
-			log.Println("Error occured: ", r)
-			debug.PrintStack()
-		}
-	}()
-
 	ctx := r.Context()
+	logger := logging.FromContext(ctx)
 
 	// Get van id
 	params := mux.Vars(r)
@@ -199,7 +198,7 @@ func (v *VanHandler) UpdateVan(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Invalid van ID"})
-		log.Println("Invalid van ID")
+		logger.Info("Invalid van ID")
 		return
 	}
 	defer r.Body.Close()
@@ -210,7 +209,8 @@ func (v *VanHandler) UpdateVan(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while reading data"})
-		panic(err)
+		logger.Error("Error occured while reading data", "error", err)
+		return
 	}
 
 	var vanReq models.Van
@@ -219,7 +219,7 @@ func (v *VanHandler) UpdateVan(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Value type is incorrect"})
-		log.Println(err)
+		logger.Info("Invalid request", "error", err)
 		return
 	}
 
@@ -229,7 +229,7 @@ func (v *VanHandler) UpdateVan(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Missing required fields"})
-		log.Println("Missing required fields")
+		logger.Info("Missing required fields")
 		return
 	}
 
@@ -238,45 +238,28 @@ func (v *VanHandler) UpdateVan(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: err.Error()})
-		log.Println(err)
+		logger.Info("Invalid request", "error", err)
 		return
 	}
 
 	// Pass data to service layer to update van
-	updatedVan, err := v.service.UpdateVan(ctx, id, &vanReq)
+	_, err = v.service.UpdateVan(ctx, id, &vanReq)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while reading data"})
-		panic(err)
-	}
-
-	if updatedVan > 0 {
-		// data is updated successfully
-		log.Println("Van data updated successfully!")
-		// Get the updated result
-		v.GetVanByID(w, r)
-	} else {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "No data present for provided Van ID or data already exists"})
-		log.Println("value of updatedVan is ", updatedVan)
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
 		return
 	}
+
+	// data is updated successfully
+	logger.Debug("Van data updated successfully!")
+	// Get the updated result
+	v.GetVanByID(w, r)
 }
 
 func (v *VanHandler) UpdateVanPartial(w http.ResponseWriter, r *http.Request) {
 
-	// panic recovery
-	defer func() {
-		var r interface{}
-		if r = recover(); r != nil {
-			log.Println("Error occured: ", r)
-			debug.PrintStack()
-		}
-	}()
-
 	ctx := r.Context()
+	logger := logging.FromContext(ctx)
 
 	// Get van id
 	params := mux.Vars(r)
@@ -288,7 +271,7 @@ func (v *VanHandler) UpdateVanPartial(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Invalid van ID"})
-		log.Println("Invalid van ID")
+		logger.Info("Invalid van ID")
 		return
 	}
 	defer r.Body.Close()
@@ -299,7 +282,8 @@ func (v *VanHandler) UpdateVanPartial(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while reading data"})
-		panic(err)
+		logger.Error("Error occured while reading data", "error", err)
+		return
 	}
 
 	var vanReq models.Van
@@ -308,7 +292,7 @@ func (v *VanHandler) UpdateVanPartial(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Value type is incorrect"})
-		log.Println(err)
+		logger.Info("Invalid request", "error", err)
 		return
 	}
 
@@ -318,7 +302,7 @@ func (v *VanHandler) UpdateVanPartial(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Missing required fields"})
-		log.Println("Missing required fields")
+		logger.Info("Missing required fields")
 		return
 	}
 
@@ -327,45 +311,28 @@ func (v *VanHandler) UpdateVanPartial(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: err.Error()})
-		log.Println(err)
+		logger.Info("Invalid request", "error", err)
 		return
 	}
 
 	// Pass data to service layer to update van
-	updatedVan, err := v.service.UpdateVan(ctx, id, &vanReq)
+	_, err = v.service.UpdateVan(ctx, id, &vanReq)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while reading data"})
-		panic(err)
-	}
-
-	if updatedVan > 0 {
-		// data is updated successfully
-		log.Println("Van data updated successfully!")
-		// Get the updated result
-		v.GetVanByID(w, r)
-	} else {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "No data present for provided Van ID or data already exists"})
-		log.Println("value of updatedVan is ", updatedVan)
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
 		return
 	}
+
+	// data is updated successfully
+	logger.Debug("Van data updated successfully!")
+	// Get the updated result
+	v.GetVanByID(w, r)
 }
 
 func (v *VanHandler) DeleteVan(w http.ResponseWriter, r *http.Request) {
 
-	// panic recovery
-	defer func() {
-		var r interface{}
-		if r = recover(); r != nil {
-			log.Println("Error occured: ", r)
-			debug.PrintStack()
-		}
-	}()
-
 	ctx := r.Context()
+	logger := logging.FromContext(ctx)
 
 	// Get van id
 	params := mux.Vars(r)
@@ -377,31 +344,20 @@ func (v *VanHandler) DeleteVan(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "Invalid van ID"})
-		log.Println("Invalid van ID")
+		logger.Info("Invalid van ID")
 		return
 	}
 
 	// Pass data to service layer to delete van
 	deletedVan, err := v.service.DeleteVan(ctx, id)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusInternalServerError, Message: "Error occured while deleting data"})
-		panic(err)
-	}
-
-	if deletedVan > 0 {
-		// data is deleted successfully
-		w.WriteHeader(http.StatusNoContent)
-		w.Header().Set("Content-Type", "application/json")
-		log.Println("value of deletedVan is ", deletedVan)
-		return
-	} else {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(routes.Response{Code: http.StatusBadRequest, Message: "No data present for provided Van ID or data already deleted"})
-		log.Println("value of deletedVan is ", deletedVan)
+		routes.WriteError(w, err)
+		logger.Info("Request failed", "error", err)
 		return
 	}
 
+	// data is deleted successfully
+	w.WriteHeader(http.StatusNoContent)
+	logger.Debug("Van deleted", "rows", deletedVan)
+
 }
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+)
+
+func (a *testAPI) createAPIKey(token string, scopes ...models.Permission) models.APIKeyCreated {
+	a.t.Helper()
+	resp := a.expect(http.StatusCreated, http.MethodPost, "/api/v1/api-key", token, models.APIKey{Name: "dealer-sync", Scopes: scopes})
+	var keys []models.APIKeyCreated
+	a.data(resp, &keys)
+	return keys[0]
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.adminToken()
+
+	created := api.createAPIKey(admin, models.PermEngineWrite)
+	if !strings.HasPrefix(created.Key, "vm_") || !strings.HasPrefix(created.Key, created.Prefix) {
+		t.Fatalf("unexpected key %q with prefix %q", created.Key, created.Prefix)
+	}
+
+	// X-API-Key header
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/engine", strings.NewReader(`{"displacement": 2000, "no-of-cylinders": 4, "material": "iron"}`))
+	req.Header.Set("X-API-Key", created.Key)
+	rec := httptest.NewRecorder()
+	api.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d, body: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+
+	// the key may also be sent as the Bearer token, scopes limit what it can do
+	engine := api.createEngine(created.Key, testEngineBody())
+	api.expect(http.StatusForbidden, http.MethodDelete, "/api/v1/engine/"+engine.ID.String(), created.Key, nil)
+
+	// routes acting on a user's own session are not open to keys
+	api.expect(http.StatusForbidden, http.MethodPost, "/api/v1/logout", created.Key, nil)
+	api.expect(http.StatusForbidden, http.MethodGet, "/api/v1/api-keys", created.Key, nil)
+
+	api.expect(http.StatusUnauthorized, http.MethodPost, "/api/v1/engine", "vm_unknown", testEngineBody())
+
+	resp := api.expect(http.StatusOK, http.MethodGet, "/api/v1/api-keys", admin, nil)
+	if strings.Contains(string(resp.Data), created.Key) {
+		t.Fatal("listing must never return the key itself")
+	}
+	var keys []models.APIKeyRecord
+	api.data(resp, &keys)
+	if len(keys) != 1 || keys[0].LastUsedAt == nil || keys[0].CreatedBy == nil {
+		t.Fatalf("unexpected key listing %+v", keys)
+	}
+}
+
+func TestAPIKeyManagement(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.adminToken()
+
+	viewer := api.viewerToken("driver")
+	api.expect(http.StatusForbidden, http.MethodPost, "/api/v1/api-key", viewer, models.APIKey{Name: "mine", Scopes: []models.Permission{models.PermVanWrite}})
+
+	past := time.Now().Add(-time.Hour)
+	api.expect(http.StatusBadRequest, http.MethodPost, "/api/v1/api-key", admin, models.APIKey{Name: "expired", Scopes: []models.Permission{models.PermVanWrite}, ExpiresAt: &past})
+	api.expect(http.StatusBadRequest, http.MethodPost, "/api/v1/api-key", admin, models.APIKey{Name: "no-scopes"})
+	api.expect(http.StatusBadRequest, http.MethodPost, "/api/v1/api-key", admin, models.APIKey{Name: "escalate", Scopes: []models.Permission{models.PermAPIKeyManage}})
+
+	created := api.createAPIKey(admin, models.PermEngineWrite)
+	api.createEngine(created.Key, testEngineBody())
+
+	path := "/api/v1/api-key/" + created.ID.String()
+	api.expect(http.StatusNoContent, http.MethodDelete, path, admin, nil)
+	api.expect(http.StatusNotFound, http.MethodDelete, path, admin, nil)
+	api.expect(http.StatusUnauthorized, http.MethodPost, "/api/v1/engine", created.Key, testEngineBody())
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/harshitrajsinha/goserver-vanmango/config"
+	"github.com/harshitrajsinha/goserver-vanmango/migrate"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+	"github.com/harshitrajsinha/goserver-vanmango/service"
+)
+
+// Bootstrap prepares the stores before serving. It applies pending migrations when
+// running on Postgres and creates the configured admin account if it does not exist
+// yet. dbClient is nil for the memory stores.
+func Bootstrap(ctx context.Context, cfg *config.Config, dbClient *sql.DB, stores Stores) error {
+
+	if dbClient != nil {
+		// concurrent instances wait on the migration lock
+		migrator, err := migrate.NewMigrator(dbClient)
+		if err != nil {
+			return err
+		}
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("%d migration(s) applied\n", applied)
+	}
+
+	username := cfg.Auth.AdminUser
+	password := cfg.Auth.AdminPassword
+	if username == "" || password == "" {
+		return nil
+	}
+
+	userService := service.NewUserService(stores.User)
+	created, err := userService.EnsureUser(ctx, &models.Credentials{Username: username, Password: password}, models.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if created {
+		log.Println("Admin account created for ", username)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testOrigin = "https://vans.example.com"
+
+func corsTestAPI(t *testing.T) *testAPI {
+	cfg := testConfig()
+	cfg.CORS.AllowedOrigins = []string{testOrigin}
+	cfg.CORS.AllowCredentials = true
+	return newTestAPIWithConfig(t, cfg)
+}
+
+func preflight(api *testAPI, origin string, path string, method string, headers string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodOptions, path, nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", method)
+	if headers != "" {
+		req.Header.Set("Access-Control-Request-Headers", headers)
+	}
+	rec := httptest.NewRecorder()
+	api.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORSPreflight(t *testing.T) {
+	api := corsTestAPI(t)
+
+	// protected and public routes both answer preflights
+	for _, path := range []string{"/api/v1/engine", "/api/v1/vans"} {
+		rec := preflight(api, testOrigin, path, http.MethodPost, "authorization, content-type, x-api-key")
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("%s: got status %d, want %d", path, rec.Code, http.StatusNoContent)
+		}
+		if rec.Header().Get("Access-Control-Allow-Origin") != testOrigin || rec.Header().Get("Access-Control-Allow-Credentials") != "true" {
+			t.Fatalf("%s: unexpected CORS headers %v", path, rec.Header())
+		}
+		if rec.Header().Get("Access-Control-Max-Age") != "600" || rec.Header().Get("Access-Control-Allow-Methods") == "" {
+			t.Fatalf("%s: unexpected CORS headers %v", path, rec.Header())
+		}
+	}
+
+	if rec := preflight(api, "https://evil.example.com", "/api/v1/engine", http.MethodPost, ""); rec.Code != http.StatusForbidden || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("unknown origin: got status %d headers %v", rec.Code, rec.Header())
+	}
+	if rec := preflight(api, testOrigin, "/api/v1/engine", "TRACE", ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("disallowed method: got status %d", rec.Code)
+	}
+	if rec := preflight(api, testOrigin, "/api/v1/engine", http.MethodPost, "X-Secret"); rec.Code != http.StatusForbidden {
+		t.Fatalf("disallowed header: got status %d", rec.Code)
+	}
+}
+
+func TestCORSActualRequest(t *testing.T) {
+	api := corsTestAPI(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/vans", nil)
+	req.Header.Set("Origin", testOrigin)
+	rec := httptest.NewRecorder()
+	api.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != testOrigin {
+		t.Fatalf("got status %d headers %v", rec.Code, rec.Header())
+	}
+	if exposed := rec.Header().Get("Access-Control-Expose-Headers"); !strings.Contains(exposed, "X-Request-ID") || !strings.Contains(exposed, "RateLimit-Remaining") || !strings.Contains(exposed, "Retry-After") {
+		t.Fatalf("got exposed headers %q, want the request ID and rate limit headers", exposed)
+	}
+
+	// without configured origins no CORS headers are sent at all
+	api = newTestAPI(t)
+	rec = httptest.NewRecorder()
+	api.router.ServeHTTP(rec, req)
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("CORS should be off by default, got headers %v", rec.Header())
+	}
+}
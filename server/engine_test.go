@@ -0,0 +1,163 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+)
+
+func TestEngineCreateAndGet(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.adminToken()
+
+	rec := api.do(http.MethodPost, "/api/v1/engine", token, testEngineBody())
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d, body: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	if !strings.HasPrefix(rec.Header().Get("Location"), "/api/v1/engine/") {
+		t.Fatalf("unexpected Location header %q", rec.Header().Get("Location"))
+	}
+
+	engine := api.createEngine(token, testEngineBody())
+	if engine.ID == uuid.Nil || engine.Displacement != 2000 || engine.Material != "iron" {
+		t.Fatalf("unexpected created engine: %+v", engine)
+	}
+
+	resp := api.expect(http.StatusOK, http.MethodGet, "/api/v1/engine/"+engine.ID.String(), "", nil)
+	var engines []models.EngineRecord
+	api.data(resp, &engines)
+	if len(engines) != 1 || engines[0].ID != engine.ID {
+		t.Fatalf("got %+v, want engine %s", engines, engine.ID)
+	}
+}
+
+func TestEngineCreateValidation(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.adminToken()
+
+	tests := []struct {
+		name string
+		body interface{}
+	}{
+		{"malformed json", `{"displacement":`},
+		{"missing fields", map[string]interface{}{"displacement": 2000}},
+		{"displacement out of range", map[string]interface{}{"displacement": 100, "no-of-cylinders": 4, "material": "iron"}},
+		{"unknown material", map[string]interface{}{"displacement": 2000, "no-of-cylinders": 4, "material": "wood"}},
+		{"odd cylinder count", map[string]interface{}{"displacement": 2000, "no-of-cylinders": 5, "material": "iron"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api.t = t
+			api.expect(http.StatusBadRequest, http.MethodPost, "/api/v1/engine", token, tt.body)
+		})
+	}
+}
+
+func TestEngineNotFound(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.adminToken()
+	missing := "/api/v1/engine/" + uuid.NewString()
+
+	api.expect(http.StatusNotFound, http.MethodGet, missing, "", nil)
+	api.expect(http.StatusNotFound, http.MethodPut, missing, token, testEngineBody())
+	api.expect(http.StatusNotFound, http.MethodPatch, missing, token, map[string]interface{}{"material": "aluminium"})
+	api.expect(http.StatusNotFound, http.MethodDelete, missing, token, nil)
+
+	api.expect(http.StatusBadRequest, http.MethodGet, "/api/v1/engine/not-a-uuid", "", nil)
+}
+
+func TestEngineList(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.adminToken()
+
+	for _, displacement := range []int{1600, 2000, 2400, 3000} {
+		body := testEngineBody()
+		body["displacement"] = displacement
+		if displacement > 2000 {
+			body["material"] = "aluminium"
+		}
+		api.createEngine(token, body)
+	}
+
+	resp := api.expect(http.StatusOK, http.MethodGet, "/api/v1/engines?material=aluminium", "", nil)
+	var engines []models.EngineRecord
+	api.data(resp, &engines)
+	if len(engines) != 2 || resp.Meta.Total != 2 {
+		t.Fatalf("material filter: got %d engines, total %d, want 2", len(engines), resp.Meta.Total)
+	}
+
+	// walk the keyset cursor through every page
+	var seen []int64
+	path := "/api/v1/engines?sort=displacement&order=desc&limit=3"
+	for path != "" {
+		resp = api.expect(http.StatusOK, http.MethodGet, path, "", nil)
+		api.data(resp, &engines)
+		for _, engine := range engines {
+			seen = append(seen, engine.Displacement)
+		}
+		path = ""
+		if resp.Meta.NextCursor != "" {
+			path = "/api/v1/engines?sort=displacement&order=desc&limit=3&cursor=" + resp.Meta.NextCursor
+		}
+	}
+	want := []int64{3000, 2400, 2000, 1600}
+	if len(seen) != len(want) {
+		t.Fatalf("got displacements %v, want %v", seen, want)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("got displacements %v, want %v", seen, want)
+		}
+	}
+
+	api.expect(http.StatusBadRequest, http.MethodGet, "/api/v1/engines?sort=material", "", nil)
+	api.expect(http.StatusBadRequest, http.MethodGet, "/api/v1/engines?limit=500", "", nil)
+}
+
+func TestEngineUpdate(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.adminToken()
+	engine := api.createEngine(token, testEngineBody())
+	path := "/api/v1/engine/" + engine.ID.String()
+
+	resp := api.expect(http.StatusOK, http.MethodPut, path, token, map[string]interface{}{"displacement": 3000, "no-of-cylinders": 6, "material": "aluminium"})
+	var engines []models.EngineRecord
+	api.data(resp, &engines)
+	if engines[0].Displacement != 3000 || engines[0].NoOfCylinders != 6 || engines[0].Material != "aluminium" {
+		t.Fatalf("PUT did not update the engine: %+v", engines[0])
+	}
+
+	resp = api.expect(http.StatusOK, http.MethodPatch, path, token, map[string]interface{}{"no-of-cylinders": 8})
+	api.data(resp, &engines)
+	if engines[0].NoOfCylinders != 8 || engines[0].Displacement != 3000 {
+		t.Fatalf("PATCH should only change the cylinders: %+v", engines[0])
+	}
+
+	api.expect(http.StatusBadRequest, http.MethodPut, path, token, map[string]interface{}{"material": "iron"})
+	api.expect(http.StatusBadRequest, http.MethodPatch, path, token, map[string]interface{}{"displacement": 99999})
+}
+
+func TestEngineDeleteCascadesToVans(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.adminToken()
+	engine := api.createEngine(token, testEngineBody())
+	van := api.createVan(token, testVanBody("Atlas", engine.ID, 5000))
+
+	api.expect(http.StatusNoContent, http.MethodDelete, "/api/v1/engine/"+engine.ID.String(), token, nil)
+	api.expect(http.StatusNotFound, http.MethodGet, "/api/v1/engine/"+engine.ID.String(), "", nil)
+	api.expect(http.StatusNotFound, http.MethodGet, "/api/v1/van/"+van.ID.String(), "", nil)
+}
+
+func TestEngineWritePermissions(t *testing.T) {
+	api := newTestAPI(t)
+	engine := api.createEngine(api.adminToken(), testEngineBody())
+	viewer := api.viewerToken("viewer")
+
+	api.expect(http.StatusForbidden, http.MethodPost, "/api/v1/engine", viewer, testEngineBody())
+	api.expect(http.StatusForbidden, http.MethodPut, "/api/v1/engine/"+engine.ID.String(), viewer, testEngineBody())
+	api.expect(http.StatusForbidden, http.MethodDelete, "/api/v1/engine/"+engine.ID.String(), viewer, nil)
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/harshitrajsinha/goserver-vanmango/migrate"
+	"github.com/harshitrajsinha/goserver-vanmango/routes"
+)
+
+// Dependencies checked by /readyz. The memory stores have nothing that can be down.
+func readinessChecks(dbClient *sql.DB) []routes.HealthCheck {
+	if dbClient == nil {
+		return []routes.HealthCheck{{
+			Name: "store",
+			Check: func(ctx context.Context) (string, error) {
+				return "memory", nil
+			},
+		}}
+	}
+
+	// embedded migrations that fail to load make the check fail rather than the router
+	migrator, loadErr := migrate.NewMigrator(dbClient)
+
+	return []routes.HealthCheck{
+		{
+			Name: "database",
+			Check: func(ctx context.Context) (string, error) {
+				return "", dbClient.PingContext(ctx)
+			},
+		},
+		{
+			// an instance is not ready until the schema it was built for is in place
+			Name: "migrations",
+			Check: func(ctx context.Context) (string, error) {
+				if loadErr != nil {
+					return "", loadErr
+				}
+				current, latest, err := migrator.Version(ctx)
+				if err != nil {
+					return "", err
+				}
+				detail := fmt.Sprintf("version %d of %d", current, latest)
+				if current < latest {
+					return detail, fmt.Errorf("schema is at version %d, this build expects %d", current, latest)
+				}
+				return detail, nil
+			},
+		},
+	}
+}
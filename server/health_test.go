@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/harshitrajsinha/goserver-vanmango/buildinfo"
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
+	"github.com/harshitrajsinha/goserver-vanmango/routes"
+)
+
+type testReadiness struct {
+	Status string `json:"status"`
+	Checks map[string]struct {
+		Status string `json:"status"`
+		Detail string `json:"detail"`
+	} `json:"checks"`
+}
+
+func decodeReadiness(t *testing.T, rec *httptest.ResponseRecorder) testReadiness {
+	t.Helper()
+	var readiness testReadiness
+	if err := json.Unmarshal(rec.Body.Bytes(), &readiness); err != nil {
+		t.Fatalf("decoding readiness: %v, body: %s", err, rec.Body.String())
+	}
+	return readiness
+}
+
+func TestProbes(t *testing.T) {
+	api := newTestAPI(t)
+
+	if rec := api.do(http.MethodGet, "/healthz", "", nil); rec.Code != http.StatusOK {
+		t.Fatalf("/healthz: got status %d", rec.Code)
+	}
+
+	rec := api.do(http.MethodGet, "/readyz", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/readyz: got status %d, body: %s", rec.Code, rec.Body.String())
+	}
+	readiness := decodeReadiness(t, rec)
+	if readiness.Status != "ok" || readiness.Checks["store"].Detail != "memory" {
+		t.Fatalf("unexpected readiness: %+v", readiness)
+	}
+
+	rec = api.do(http.MethodGet, "/version", "", nil)
+	var info buildinfo.Info
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatalf("decoding version: %v", err)
+	}
+	if info.GoVersion != runtime.Version() || info.Commit == "" || info.BuildTime == "" {
+		t.Fatalf("unexpected build info: %+v", info)
+	}
+}
+
+func TestReadinessReportsFailingDependency(t *testing.T) {
+	buf := captureLogs(t)
+	handler := routes.NewHealthHandler(
+		routes.HealthCheck{Name: "database", Check: func(ctx context.Context) (string, error) {
+			return "", nil
+		}},
+		routes.HealthCheck{Name: "migrations", Check: func(ctx context.Context) (string, error) {
+			return "version 3 of 5", errors.New("schema is behind")
+		}},
+	)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	handler.Readiness(rec, req.WithContext(logging.With(req.Context(), "request-id", "probe-1")))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+
+	readiness := decodeReadiness(t, rec)
+	if readiness.Status != "unavailable" || readiness.Checks["database"].Status != "ok" {
+		t.Fatalf("unexpected readiness: %+v", readiness)
+	}
+	if migrations := readiness.Checks["migrations"]; migrations.Status != "unavailable" || migrations.Detail != "version 3 of 5" {
+		t.Fatalf("unexpected migrations check: %+v", migrations)
+	}
+
+	// the cause is logged with the request's logger
+	records := logRecords(t, buf)
+	if len(records) != 1 || records[0]["request-id"] != "probe-1" || records[0]["check"] != "migrations" || records[0]["error"] != "schema is behind" {
+		t.Fatalf("got log records %v, want one for the failed check", records)
+	}
+}
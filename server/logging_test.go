@@ -0,0 +1,89 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
+)
+
+// Route every log record of the test into a buffer, one JSON object per line
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buf, slog.LevelDebug))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("log line is not JSON: %v, line: %s", err, scanner.Text())
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestRequestLogging(t *testing.T) {
+	buf := captureLogs(t)
+	api := newTestAPI(t)
+	token := api.adminToken()
+
+	missing := "/api/v1/engine/" + uuid.NewString()
+	req := httptest.NewRequest(http.MethodDelete, missing, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Request-ID", "trace-123")
+	rec := httptest.NewRecorder()
+	api.router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if got := rec.Header().Get("X-Request-ID"); got != "trace-123" {
+		t.Fatalf("request ID should be echoed back, got %q", got)
+	}
+
+	var access map[string]interface{}
+	var handlerLogs int
+	for _, record := range logRecords(t, buf) {
+		if record["request-id"] != "trace-123" {
+			continue
+		}
+		if record["msg"] == "request completed" {
+			access = record
+		} else {
+			handlerLogs++
+		}
+	}
+	if access == nil {
+		t.Fatalf("no access log line for the request, logs: %s", buf.String())
+	}
+	if access["route"] != "/api/v1/engine/{id}" || access["method"] != http.MethodDelete || access["status"] != float64(http.StatusNotFound) || access["user"] == nil {
+		t.Fatalf("unexpected access log line: %v", access)
+	}
+	if handlerLogs == 0 {
+		t.Fatal("handler logs should carry the request ID")
+	}
+
+	// unmatched paths and unusable caller IDs still get a fresh ID
+	req = httptest.NewRequest(http.MethodGet, "/no-such-route", nil)
+	req.Header.Set("X-Request-ID", "bad id\n")
+	rec = httptest.NewRecorder()
+	api.router.ServeHTTP(rec, req)
+	if _, err := uuid.Parse(rec.Header().Get("X-Request-ID")); err != nil {
+		t.Fatalf("expected a generated request ID, got %q", rec.Header().Get("X-Request-ID"))
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harshitrajsinha/goserver-vanmango/config"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+)
+
+func lockoutTestAPI(t *testing.T, maxAttempts int, maxAttemptsPerIP int) *testAPI {
+	cfg := testConfig()
+	cfg.TrustProxy = true
+	cfg.Auth.Lockout = config.LockoutConfig{MaxAttempts: maxAttempts, MaxAttemptsPerIP: maxAttemptsPerIP, Duration: time.Minute, MaxDuration: time.Hour}
+	return newTestAPIWithConfig(t, cfg)
+}
+
+// Log in from the given client address, as forwarded by the proxy
+func (a *testAPI) loginFrom(ip string, username string, password string) *httptest.ResponseRecorder {
+	a.t.Helper()
+	body := `{"username": "` + username + `", "password": "` + password + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/login", strings.NewReader(body))
+	req.Header.Set("X-Forwarded-For", ip)
+	rec := httptest.NewRecorder()
+	a.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLoginLockoutPerUsername(t *testing.T) {
+	buf := captureLogs(t)
+	api := lockoutTestAPI(t, 3, 100)
+
+	// a successful login clears earlier failures
+	api.loginFrom("203.0.113.1", testAdminUser, "wrong-password")
+	api.loginFrom("203.0.113.1", testAdminUser, "wrong-password")
+	if rec := api.loginFrom("203.0.113.1", testAdminUser, testAdminPassword); rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusCreated)
+	}
+
+	for i := 0; i < 3; i++ {
+		if rec := api.loginFrom("203.0.113.1", "ADMIN", "wrong-password"); rec.Code != http.StatusBadRequest {
+			t.Fatalf("attempt %d: got status %d, want %d", i+1, rec.Code, http.StatusBadRequest)
+		}
+	}
+
+	// locked from any address, even with the right password
+	rec := api.loginFrom("203.0.113.2", testAdminUser, testAdminPassword)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" {
+		t.Fatalf("got status %d Retry-After %q, want 429 after 60s", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	var failures, successes int
+	for _, record := range logRecords(t, buf) {
+		if record["msg"] == "Login attempt" && record["ip"] == "203.0.113.1" {
+			switch record["outcome"] {
+			case string(models.LoginFailed):
+				failures++
+			case string(models.LoginSucceeded):
+				successes++
+			}
+		}
+	}
+	if failures != 5 || successes != 1 {
+		t.Fatalf("got %d failed and %d successful logins in the audit log, want 5 and 1", failures, successes)
+	}
+}
+
+func TestLoginLockoutPerIP(t *testing.T) {
+	api := lockoutTestAPI(t, 100, 2)
+
+	api.loginFrom("203.0.113.1", "alice", "wrong-password")
+	api.loginFrom("203.0.113.1", "bob", "wrong-password")
+
+	if rec := api.loginFrom("203.0.113.1", testAdminUser, testAdminPassword); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if rec := api.loginFrom("203.0.113.2", testAdminUser, testAdminPassword); rec.Code != http.StatusCreated {
+		t.Fatalf("other addresses should not be locked, got status %d", rec.Code)
+	}
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	api := newTestAPI(t)
+
+	id := uuid.NewString()
+	api.expect(http.StatusNotFound, http.MethodGet, "/api/v1/van/"+id, "", nil)
+	api.expect(http.StatusNotFound, http.MethodGet, "/api/v1/van/"+uuid.NewString(), "", nil)
+
+	rec := api.do(http.MethodGet, "/metrics", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		`vanmango_http_requests_total{method="GET",route="/api/v1/van/{id}",status="404"}`,
+		`vanmango_http_request_duration_seconds_bucket{method="GET",route="/api/v1/van/{id}"`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output is missing %s", want)
+		}
+	}
+	if strings.Contains(body, id) {
+		t.Error("raw paths should never be used as route labels")
+	}
+}
+
+func TestMetricsCanBeDisabled(t *testing.T) {
+	cfg := testConfig()
+	cfg.Features.Metrics = false
+	api := newTestAPIWithConfig(t, cfg)
+
+	if rec := api.do(http.MethodGet, "/metrics", "", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
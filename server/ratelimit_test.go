@@ -0,0 +1,113 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/harshitrajsinha/goserver-vanmango/config"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+)
+
+func rateLimitTestAPI(t *testing.T, public int, protected int) *testAPI {
+	cfg := testConfig()
+	cfg.TrustProxy = true
+	cfg.RateLimit = config.RateLimitConfig{
+		Public:    config.RateLimit{Requests: public, Period: time.Minute},
+		Protected: config.RateLimit{Requests: protected, Period: time.Minute},
+	}
+	return newTestAPIWithConfig(t, cfg)
+}
+
+func getFrom(api *testAPI, ip string, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("X-Forwarded-For", ip)
+	rec := httptest.NewRecorder()
+	api.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPublicRateLimit(t *testing.T) {
+	api := rateLimitTestAPI(t, 3, 0)
+
+	for _, remaining := range []string{"2", "1", "0"} {
+		rec := getFrom(api, "203.0.113.1", "/api/v1/vans")
+		if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "3" || rec.Header().Get("RateLimit-Remaining") != remaining {
+			t.Fatalf("got status %d headers %v, want 200 with %s remaining", rec.Code, rec.Header(), remaining)
+		}
+	}
+
+	rec := getFrom(api, "203.0.113.1", "/api/v1/engines")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "20" || rec.Header().Get("RateLimit-Policy") != "3;w=60" {
+		t.Fatalf("got status %d headers %v, want 429 retrying after 20s", rec.Code, rec.Header())
+	}
+
+	// every client address has its own bucket
+	if rec := getFrom(api, "203.0.113.2", "/api/v1/vans"); rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestProtectedRateLimitPerCaller(t *testing.T) {
+	api := rateLimitTestAPI(t, 100, 2)
+	admin := api.adminToken()
+	key := api.createAPIKey(admin, models.PermEngineWrite).Key
+
+	api.createEngine(admin, testEngineBody())
+	api.expect(http.StatusTooManyRequests, http.MethodPost, "/api/v1/engine", admin, testEngineBody())
+
+	// the key is a separate caller from the admin who minted it
+	api.createEngine(key, testEngineBody())
+	api.createEngine(key, testEngineBody())
+	api.expect(http.StatusTooManyRequests, http.MethodPost, "/api/v1/engine", key, testEngineBody())
+
+	// public routes keep their own, larger limit
+	api.expect(http.StatusOK, http.MethodGet, "/api/v1/engines", "", nil)
+}
+
+func TestRateLimitScopes(t *testing.T) {
+	api := rateLimitTestAPI(t, 2, 100)
+	admin := api.adminToken()
+
+	// login spent one public request, protected calls from the same address spend none
+	for i := 0; i < 3; i++ {
+		api.createEngine(admin, testEngineBody())
+	}
+	if rec := getFrom(api, "192.0.2.1", "/api/v1/engines"); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("got status %d headers %v, want 200 with the last public request", rec.Code, rec.Header())
+	}
+	if rec := getFrom(api, "192.0.2.1", "/api/v1/vans"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+
+	// probes and metrics are never limited
+	for _, path := range []string{"/healthz", "/readyz", "/version", "/metrics"} {
+		if rec := getFrom(api, "192.0.2.1", path); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("%s: got status %d headers %v, want 200 without limit headers", path, rec.Code, rec.Header())
+		}
+	}
+}
+
+func TestRateLimitBeforeAuth(t *testing.T) {
+	cfg := testConfig()
+	cfg.TrustProxy = true
+	cfg.RateLimit.Auth = config.RateLimit{Requests: 3, Period: time.Minute}
+	api := newTestAPIWithConfig(t, cfg)
+
+	// guessed API keys are turned away by the limit before they are looked up
+	for i := 0; i < 3; i++ {
+		api.expect(http.StatusUnauthorized, http.MethodPost, "/api/v1/engine", "vm_guessed-key", testEngineBody())
+	}
+	api.expect(http.StatusTooManyRequests, http.MethodPost, "/api/v1/engine", "vm_guessed-key", testEngineBody())
+	api.expect(http.StatusTooManyRequests, http.MethodPost, "/api/v1/engine", "", testEngineBody())
+
+	// other client addresses keep their own bucket
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/logout", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.9")
+	rec := httptest.NewRecorder()
+	api.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/harshitrajsinha/goserver-vanmango/middleware"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+	"github.com/harshitrajsinha/goserver-vanmango/store"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestRecoverMiddleware(t *testing.T) {
+	buf := captureLogs(t)
+
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/after-write" {
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"code":`))
+		}
+		panic("boom")
+	})
+	handler := middleware.RequestLogger(slog.Default())(middleware.Recover(panicking))
+
+	req := httptest.NewRequest(http.MethodGet, "/before-write", nil)
+	req.Header.Set("X-Request-ID", "panic-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), `"code":500`) {
+		t.Fatalf("got status %d body %s, want a JSON 500", rec.Code, rec.Body.String())
+	}
+
+	// a started response cannot be fixed, the server is told to drop the connection
+	rec = httptest.NewRecorder()
+	func() {
+		defer func() {
+			if recovered := recover(); recovered != http.ErrAbortHandler {
+				t.Fatalf("got panic %v, want http.ErrAbortHandler", recovered)
+			}
+		}()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/after-write", nil))
+	}()
+	if rec.Body.String() != `{"code":` {
+		t.Fatalf("nothing may be appended to a started response, got body %q", rec.Body.String())
+	}
+
+	var logged, aborted bool
+	for _, record := range logRecords(t, buf) {
+		if record["msg"] == "Recovered from panic" && record["request-id"] == "panic-1" {
+			logged = record["panic"] == "boom" && strings.Contains(record["stack"].(string), "goroutine")
+		}
+		if record["msg"] == "request completed" && record["path"] == "/after-write" {
+			aborted = record["aborted"] == true && record["status"] == float64(http.StatusInternalServerError)
+		}
+	}
+	if !logged {
+		t.Fatalf("panic should be logged with its stack and request ID, logs: %s", buf.String())
+	}
+	if !aborted {
+		t.Fatalf("the dropped response should still get an access log line, logs: %s", buf.String())
+	}
+}
+
+// Van store whose list query panics, standing in for a bug deep in a handler
+type panickingVanStore struct {
+	store.VanStoreInterface
+}
+
+func (panickingVanStore) GetAllVan(ctx context.Context, filter *models.VanFilter) ([]models.VanRecord, *models.PageInfo, error) {
+	panic("van store bug")
+}
+
+func TestPanicIsMeasuredAndTraced(t *testing.T) {
+	exporter := captureSpans(t)
+	api := newTestAPI(t)
+	stores := api.stores
+	stores.Van = panickingVanStore{stores.Van}
+	api.router = NewRouter(testConfig(), nil, stores)
+
+	api.expect(http.StatusInternalServerError, http.MethodGet, "/api/v1/vans", "", nil)
+
+	body := api.do(http.MethodGet, "/metrics", "", nil).Body.String()
+	if !strings.Contains(body, `vanmango_http_requests_total{method="GET",route="/api/v1/vans",status="500"}`) {
+		t.Fatalf("the panic should be counted as a 500, metrics: %s", body)
+	}
+
+	for _, span := range exporter.GetSpans() {
+		if span.Name == "GET /api/v1/vans" {
+			if span.Status.Code != codes.Error {
+				t.Fatalf("server span should be marked as failed, got status %+v", span.Status)
+			}
+			return
+		}
+	}
+	t.Fatal("missing server span for the panicking request")
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/harshitrajsinha/goserver-vanmango/config"
+	"github.com/harshitrajsinha/goserver-vanmango/metrics"
+	"github.com/harshitrajsinha/goserver-vanmango/middleware"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+	"github.com/harshitrajsinha/goserver-vanmango/ratelimit"
+	"github.com/harshitrajsinha/goserver-vanmango/routes"
+	apiV1 "github.com/harshitrajsinha/goserver-vanmango/routes/v1"
+	"github.com/harshitrajsinha/goserver-vanmango/service"
+	"github.com/harshitrajsinha/goserver-vanmango/signing"
+)
+
+// NewRouter wires stores, services and handlers and registers every API route.
+// It is shared by the Vercel handler and the standalone server binary.
+// dbClient is nil for the memory stores. Requests are logged with the default slog logger.
+func NewRouter(cfg *config.Config, dbClient *sql.DB, stores Stores) http.Handler {
+
+	router := mux.NewRouter()
+	router.Use(middleware.Tracing, middleware.RouteLogger, middleware.Metrics)
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Server is functioning"})
+	}).Methods("GET")
+
+	// Probes for the load balancer and build details
+	healthHandler := routes.NewHealthHandler(readinessChecks(dbClient)...)
+	router.HandleFunc("/healthz", healthHandler.Liveness).Methods(http.MethodGet)
+	router.HandleFunc("/readyz", healthHandler.Readiness).Methods(http.MethodGet)
+	router.HandleFunc("/version", healthHandler.Version).Methods(http.MethodGet)
+	if cfg.Features.Metrics {
+		if dbClient != nil {
+			if err := metrics.RegisterDB(dbClient, "vanmango"); err != nil {
+				slog.Error("Registering database metrics failed", "error", err)
+			}
+		}
+		router.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
+	}
+
+	// Initialize engine constructors
+	engineService := service.NewEngineService(stores.Engine)
+	engineHandler := apiV1.NewEngineHandler(engineService)
+
+	// Initialize van constructors
+	vanService := service.NewVanService(stores.Van)
+	vanHandler := apiV1.NewVanHandler(vanService)
+
+	// Without configured keys (memory driver only) tokens are signed with a key that
+	// lives as long as the process
+	keys := &cfg.Auth.SigningKeys
+	if keys.Len() == 0 {
+		slog.Warn("No JWT_SIGNING_KEYS configured, signing tokens with an ephemeral key")
+		keys = signing.Ephemeral()
+	}
+	jwksHandler := routes.NewJWKSHandler(keys)
+
+	// Initialize user and token constructors
+	userService := service.NewUserService(stores.User)
+	tokenService := service.NewTokenService(stores.Token, stores.User, cfg.Auth.RefreshTokenTTL)
+	loginGuard := service.NewLoginGuard(stores.LoginAttempts, stores.LoginEvents, cfg.Auth.Lockout)
+	userHandler := apiV1.NewUserHandler(userService, tokenService, loginGuard, keys, cfg.Auth)
+
+	// Initialize API key constructors
+	apiKeyService := service.NewAPIKeyService(stores.APIKey)
+	apiKeyHandler := apiV1.NewAPIKeyHandler(apiKeyService)
+
+	// -------------------- Public routes
+
+	// limited per client address; the probes and /metrics above stay unlimited so that
+	// callers sharing an address cannot get the instance taken out of rotation
+	publicRouter := router.NewRoute().Subrouter()
+	publicRouter.Use(middleware.RateLimit(stores.RateLimiter, "public", ratelimit.Limit(cfg.RateLimit.Public)))
+
+	// Public keys for verifying access tokens
+	publicRouter.HandleFunc("/.well-known/jwks.json", jwksHandler.KeySet).Methods(http.MethodGet)
+
+	// Routes for Engine
+	publicRouter.HandleFunc("/api/v1/engine/{id}", engineHandler.GetEngineByID).Methods(http.MethodGet)
+	publicRouter.HandleFunc("/api/v1/engines", engineHandler.GetAllEngine).Methods(http.MethodGet)
+	// Routes for Van
+	publicRouter.HandleFunc("/api/v1/van/{id}", vanHandler.GetVanByID).Methods(http.MethodGet)
+	publicRouter.HandleFunc("/api/v1/vans", vanHandler.GetAllVan).Methods(http.MethodGet)
+	if cfg.Features.Search {
+		publicRouter.HandleFunc("/api/v1/vans/search", vanHandler.SearchVan).Methods(http.MethodGet)
+	}
+
+	// Routes for User
+	if cfg.Features.Registration {
+		publicRouter.HandleFunc("/api/v1/register", userHandler.Register).Methods(http.MethodPost)
+	}
+	publicRouter.HandleFunc("/api/v1/login", userHandler.Login).Methods(http.MethodPost)
+	publicRouter.HandleFunc("/api/v1/token/refresh", userHandler.RefreshToken).Methods(http.MethodPost)
+
+	// -------------------- Protected routes
+
+	protectedRouter := router.PathPrefix("/").Subrouter()
+	// limited per client address before authentication so guessed tokens and keys
+	// are limited too, then per API key and user once the caller is known
+	protectedRouter.Use(middleware.RateLimit(stores.RateLimiter, "auth", ratelimit.Limit(cfg.RateLimit.Auth)))
+	protectedRouter.Use(middleware.AuthMiddleware(keys, cfg.Auth, tokenService, apiKeyService))
+	protectedRouter.Use(middleware.RateLimit(stores.RateLimiter, "protected", ratelimit.Limit(cfg.RateLimit.Protected)))
+
+	// Routes for User
+	protectedRouter.Handle("/api/v1/logout", middleware.RequireUser(userHandler.Logout)).Methods(http.MethodPost)
+	protectedRouter.Handle("/api/v1/user/password", middleware.RequireUser(userHandler.ChangePassword)).Methods(http.MethodPut)
+	protectedRouter.Handle("/api/v1/user/{id}/role", middleware.RequirePermission(models.PermUserManage, userHandler.SetRole)).Methods(http.MethodPatch)
+
+	// Routes for API keys, keys cannot be granted api-key:manage so only admins reach these
+	protectedRouter.Handle("/api/v1/api-key", middleware.RequirePermission(models.PermAPIKeyManage, apiKeyHandler.CreateAPIKey)).Methods(http.MethodPost)
+	protectedRouter.Handle("/api/v1/api-keys", middleware.RequirePermission(models.PermAPIKeyManage, apiKeyHandler.GetAllAPIKey)).Methods(http.MethodGet)
+	protectedRouter.Handle("/api/v1/api-key/{id}", middleware.RequirePermission(models.PermAPIKeyManage, apiKeyHandler.RevokeAPIKey)).Methods(http.MethodDelete)
+
+	// Routes for Engine
+	protectedRouter.Handle("/api/v1/engine", middleware.RequirePermission(models.PermEngineWrite, engineHandler.CreateEngine)).Methods(http.MethodPost)
+	protectedRouter.Handle("/api/v1/engine/{id}", middleware.RequirePermission(models.PermEngineWrite, engineHandler.UpdateEngine)).Methods(http.MethodPut)
+	protectedRouter.Handle("/api/v1/engine/{id}", middleware.RequirePermission(models.PermEngineWrite, engineHandler.UpdateEnginePartial)).Methods(http.MethodPatch)
+	protectedRouter.Handle("/api/v1/engine/{id}", middleware.RequirePermission(models.PermEngineDelete, engineHandler.DeleteEngine)).Methods(http.MethodDelete)
+
+	// Routes for Van
+	protectedRouter.Handle("/api/v1/van", middleware.RequirePermission(models.PermVanWrite, vanHandler.CreateVan)).Methods(http.MethodPost)
+	protectedRouter.Handle("/api/v1/van/{id}", middleware.RequirePermission(models.PermVanWrite, vanHandler.UpdateVan)).Methods(http.MethodPut)
+	protectedRouter.Handle("/api/v1/van/{id}", middleware.RequirePermission(models.PermVanWrite, vanHandler.UpdateVanPartial)).Methods(http.MethodPatch)
+	protectedRouter.Handle("/api/v1/van/{id}", middleware.RequirePermission(models.PermVanDelete, vanHandler.DeleteVan)).Methods(http.MethodDelete)
+
+	// the request logger wraps the router so unmatched paths get an ID and a log line too,
+	// panics are recovered inside it so they are logged with that ID. CORS sits in front
+	// of route matching to answer preflights for the public and protected routes alike.
+	// The client address is resolved first so every layer sees the same one.
+	handler := middleware.CORS(cfg.CORS)(router)
+	handler = middleware.RequestLogger(slog.Default())(middleware.Recover(handler))
+	return middleware.RealIP(cfg.TrustProxy)(handler)
+}
@@ -0,0 +1,226 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/harshitrajsinha/goserver-vanmango/config"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+	"github.com/harshitrajsinha/goserver-vanmango/service"
+)
+
+const (
+	testAdminUser     = "admin"
+	testAdminPassword = "admin-password"
+)
+
+// Decoded routes.Response with the data left raw so each test can pick its type
+type testResponse struct {
+	Code    int              `json:"code"`
+	Message string           `json:"message"`
+	Data    json.RawMessage  `json:"data"`
+	Meta    *models.PageInfo `json:"meta"`
+}
+
+// Router built on fresh memory stores with an admin account, no database or network needed
+type testAPI struct {
+	t      *testing.T
+	router http.Handler
+	stores Stores
+}
+
+func testConfig() *config.Config {
+	cfg := config.Default()
+	cfg.Store.Driver = config.StoreDriverMemory
+	return cfg
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	return newTestAPIWithConfig(t, testConfig())
+}
+
+func newTestAPIWithConfig(t *testing.T, cfg *config.Config) *testAPI {
+	t.Helper()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("invalid test config: %v", err)
+	}
+
+	stores := NewMemoryStores()
+	_, err := service.NewUserService(stores.User).EnsureUser(context.Background(), &models.Credentials{Username: testAdminUser, Password: testAdminPassword}, models.RoleAdmin)
+	if err != nil {
+		t.Fatalf("creating admin account: %v", err)
+	}
+
+	return &testAPI{t: t, router: NewRouter(cfg, nil, stores), stores: stores}
+}
+
+// Send a request through the router, body is JSON encoded unless it is already a string
+func (a *testAPI) do(method string, path string, token string, body interface{}) *httptest.ResponseRecorder {
+	a.t.Helper()
+
+	var payload []byte
+	switch value := body.(type) {
+	case nil:
+	case string:
+		payload = []byte(value)
+	default:
+		var err error
+		payload, err = json.Marshal(value)
+		if err != nil {
+			a.t.Fatalf("encoding request body: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	a.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// Send a request and fail the test unless the expected status comes back
+func (a *testAPI) expect(status int, method string, path string, token string, body interface{}) testResponse {
+	a.t.Helper()
+
+	rec := a.do(method, path, token, body)
+	if rec.Code != status {
+		a.t.Fatalf("%s %s: got status %d, want %d, body: %s", method, path, rec.Code, status, rec.Body.String())
+	}
+
+	var resp testResponse
+	if rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			a.t.Fatalf("%s %s: decoding response: %v", method, path, err)
+		}
+	}
+	return resp
+}
+
+// Unmarshal the data of a response into dest
+func (a *testAPI) data(resp testResponse, dest interface{}) {
+	a.t.Helper()
+	if err := json.Unmarshal(resp.Data, dest); err != nil {
+		a.t.Fatalf("decoding response data: %v, data: %s", err, resp.Data)
+	}
+}
+
+type testTokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh-token"`
+}
+
+func (a *testAPI) login(username string, password string) testTokens {
+	a.t.Helper()
+	resp := a.expect(http.StatusCreated, http.MethodPost, "/api/v1/login", "", models.Credentials{Username: username, Password: password})
+	var tokens []testTokens
+	a.data(resp, &tokens)
+	return tokens[0]
+}
+
+func (a *testAPI) adminToken() string {
+	return a.login(testAdminUser, testAdminPassword).Token
+}
+
+// Register an account and log it in, the account keeps the viewer role
+func (a *testAPI) viewerToken(username string) string {
+	a.t.Helper()
+	credentials := models.Credentials{Username: username, Password: "viewer-password"}
+	a.expect(http.StatusCreated, http.MethodPost, "/api/v1/register", "", credentials)
+	return a.login(credentials.Username, credentials.Password).Token
+}
+
+func testEngineBody() map[string]interface{} {
+	return map[string]interface{}{"displacement": 2000, "no-of-cylinders": 4, "material": "iron"}
+}
+
+func testVanBody(name string, engineID uuid.UUID, price int64) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        name,
+		"brand":       "Mango",
+		"description": "Camper van built for long " + name + " trips",
+		"category":    "rugged",
+		"fuel-type":   "diesel",
+		"engine-id":   engineID,
+		"price":       price,
+		"image-url":   "https://example.com/" + name + ".png",
+	}
+}
+
+func (a *testAPI) createEngine(token string, body map[string]interface{}) models.EngineRecord {
+	a.t.Helper()
+	resp := a.expect(http.StatusCreated, http.MethodPost, "/api/v1/engine", token, body)
+	var engines []models.EngineRecord
+	a.data(resp, &engines)
+	return engines[0]
+}
+
+func (a *testAPI) createVan(token string, body map[string]interface{}) models.VanRecord {
+	a.t.Helper()
+	resp := a.expect(http.StatusCreated, http.MethodPost, "/api/v1/van", token, body)
+	var vans []models.VanRecord
+	a.data(resp, &vans)
+	return vans[0]
+}
+
+func TestRootRoute(t *testing.T) {
+	api := newTestAPI(t)
+
+	rec := api.do(http.MethodGet, "/", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestProtectedRoutesRequireToken(t *testing.T) {
+	api := newTestAPI(t)
+	id := uuid.NewString()
+
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/api/v1/engine"},
+		{http.MethodPut, "/api/v1/engine/" + id},
+		{http.MethodPatch, "/api/v1/engine/" + id},
+		{http.MethodDelete, "/api/v1/engine/" + id},
+		{http.MethodPost, "/api/v1/van"},
+		{http.MethodPut, "/api/v1/van/" + id},
+		{http.MethodPatch, "/api/v1/van/" + id},
+		{http.MethodDelete, "/api/v1/van/" + id},
+		{http.MethodPost, "/api/v1/logout"},
+		{http.MethodPut, "/api/v1/user/password"},
+		{http.MethodPatch, "/api/v1/user/" + id + "/role"},
+	}
+
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			api.t = t
+			api.expect(http.StatusUnauthorized, route.method, route.path, "", nil)
+			api.expect(http.StatusUnauthorized, route.method, route.path, "not-a-jwt", nil)
+		})
+	}
+}
+
+func TestFeatureFlagsDisableRoutes(t *testing.T) {
+	cfg := testConfig()
+	cfg.Features.Registration = false
+	cfg.Features.Search = false
+	api := newTestAPIWithConfig(t, cfg)
+
+	if rec := api.do(http.MethodGet, "/api/v1/vans/search?q=van", "", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("search should be disabled, got status %d", rec.Code)
+	}
+	if rec := api.do(http.MethodPost, "/api/v1/register", "", models.Credentials{Username: "driver", Password: "driver-password"}); rec.Code == http.StatusCreated {
+		t.Fatal("registration should be disabled")
+	}
+	api.login(testAdminUser, testAdminPassword)
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/harshitrajsinha/goserver-vanmango/signing"
+)
+
+// PEM encoded Ed25519 key, the headers set its Key-ID and Active-From
+func testSigningKey(t *testing.T, headers map[string]string) (string, ed25519.PrivateKey) {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Headers: headers, Bytes: der})), private
+}
+
+func tokenKeyID(t *testing.T, token string) string {
+	t.Helper()
+	parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("parsing token: %v", err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestSigningKeyRotation(t *testing.T) {
+	current, currentKey := testSigningKey(t, map[string]string{"Key-ID": "current"})
+	next, _ := testSigningKey(t, map[string]string{"Key-ID": "next", "Active-From": time.Now().Add(time.Hour).Format(time.RFC3339)})
+	cfg := testConfig()
+	if err := cfg.Auth.SigningKeys.UnmarshalText([]byte(next + current)); err != nil {
+		t.Fatal(err)
+	}
+	api := newTestAPIWithConfig(t, cfg)
+
+	// the next key is published ahead of its activation but does not sign yet
+	rec := api.do(http.MethodGet, "/.well-known/jwks.json", "", nil)
+	var jwks signing.JWKS
+	if err := json.NewDecoder(rec.Body).Decode(&jwks); err != nil || rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") == "" {
+		t.Fatalf("got status %d headers %v (%v), want the cacheable key set", rec.Code, rec.Header(), err)
+	}
+	if len(jwks.Keys) != 2 || jwks.Keys[0].KeyID != "current" || jwks.Keys[1].KeyID != "next" {
+		t.Fatalf("got keys %+v, want current and next", jwks.Keys)
+	}
+	if jwks.Keys[0].KeyType != "OKP" || jwks.Keys[0].X != jwt.EncodeSegment(currentKey.Public().(ed25519.PublicKey)) {
+		t.Fatalf("published key %+v does not match the configured one", jwks.Keys[0])
+	}
+
+	token := api.adminToken()
+	if kid := tokenKeyID(t, token); kid != "current" {
+		t.Fatalf("token signed with key %q, want current", kid)
+	}
+	api.expect(http.StatusCreated, http.MethodPost, "/api/v1/engine", token, testEngineBody())
+}
+
+func TestTokenSignatureChecks(t *testing.T) {
+	api := newTestAPI(t)
+	kid := tokenKeyID(t, api.adminToken())
+	claims := jwt.MapClaims{"sub": "admin", "role": "admin", "jti": uuid.NewString(), "exp": time.Now().Add(time.Minute).Unix()}
+
+	// an HMAC token with the kid of a real key must not be checked against its public half
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	hmac.Header["kid"] = kid
+	forged, err := hmac.SignedString([]byte("guessed-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.expect(http.StatusUnauthorized, http.MethodPost, "/api/v1/engine", forged, testEngineBody())
+
+	// a key of the right type that is not in the set
+	_, otherKey := testSigningKey(t, nil)
+	for _, id := range []string{kid, "unknown"} {
+		other := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+		other.Header["kid"] = id
+		forged, err := other.SignedString(otherKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		api.expect(http.StatusUnauthorized, http.MethodPost, "/api/v1/engine", forged, testEngineBody())
+	}
+}
+
+func TestTokenClaimsChecks(t *testing.T) {
+	pemKey, private := testSigningKey(t, map[string]string{"Key-ID": "current"})
+	cfg := testConfig()
+	if err := cfg.Auth.SigningKeys.UnmarshalText([]byte(pemKey)); err != nil {
+		t.Fatal(err)
+	}
+	api := newTestAPIWithConfig(t, cfg)
+
+	issued := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(api.adminToken(), issued); err != nil {
+		t.Fatal(err)
+	}
+	if issued["iss"] != "vanmango" || issued["aud"] != "vanmango-api" || issued["username"] != testAdminUser || issued["nbf"] == nil || issued["jti"] == nil {
+		t.Fatalf("got claims %v, want iss, aud, nbf, jti and the username", issued)
+	}
+
+	now := time.Now()
+	sign := func(change func(claims jwt.MapClaims)) string {
+		claims := jwt.MapClaims{
+			"sub": issued["sub"], "username": testAdminUser, "role": "admin", "iss": "vanmango", "aud": "vanmango-api",
+			"jti": uuid.NewString(), "nbf": now.Unix(), "exp": now.Add(time.Minute).Unix(),
+		}
+		change(claims)
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+		token.Header["kid"] = "current"
+		signed, err := token.SignedString(private)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	// the same claims are accepted, so each rejection below is down to the one change
+	api.expect(http.StatusCreated, http.MethodPost, "/api/v1/engine", sign(func(jwt.MapClaims) {}), testEngineBody())
+	for name, change := range map[string]func(claims jwt.MapClaims){
+		"other issuer":   func(claims jwt.MapClaims) { claims["iss"] = "someone-else" },
+		"other audience": func(claims jwt.MapClaims) { claims["aud"] = "billing-api" },
+		"not yet valid":  func(claims jwt.MapClaims) { claims["nbf"] = now.Add(time.Hour).Unix() },
+		"no nbf":         func(claims jwt.MapClaims) { delete(claims, "nbf") },
+		"no exp":         func(claims jwt.MapClaims) { delete(claims, "exp") },
+		"no jti":         func(claims jwt.MapClaims) { delete(claims, "jti") },
+		"jti not a UUID": func(claims jwt.MapClaims) { claims["jti"] = "forged" },
+	} {
+		if rec := api.do(http.MethodPost, "/api/v1/engine", sign(change), testEngineBody()); rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s: got status %d, want %d", name, rec.Code, http.StatusUnauthorized)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"database/sql"
+
+	"github.com/harshitrajsinha/goserver-vanmango/ratelimit"
+	"github.com/harshitrajsinha/goserver-vanmango/store"
+	"github.com/harshitrajsinha/goserver-vanmango/store/memory"
+)
+
+// Stores groups the store implementations the router is built on
+type Stores struct {
+	Engine store.EngineStoreInterface
+	Van    store.VanStoreInterface
+	User   store.UserStoreInterface
+	Token  store.TokenStoreInterface
+	APIKey store.APIKeyStoreInterface
+	// failed login counters stay in process memory with either driver
+	LoginAttempts store.LoginAttemptStoreInterface
+	LoginEvents   store.LoginEventStoreInterface
+	// request buckets for rate limiting, in process memory with either driver
+	RateLimiter ratelimit.Limiter
+}
+
+// NewPostgresStores returns stores backed by the given database
+func NewPostgresStores(dbClient *sql.DB) Stores {
+	return Stores{
+		Engine: store.NewEngineStore(dbClient),
+		Van:    store.NewVanStore(dbClient),
+		User:   store.NewUserStore(dbClient),
+		Token:  store.NewTokenStore(dbClient),
+		APIKey: store.NewAPIKeyStore(dbClient),
+
+		LoginAttempts: memory.NewLoginAttemptStore(),
+		LoginEvents:   store.NewLoginEventStore(dbClient),
+		RateLimiter:   ratelimit.NewMemoryLimiter(),
+	}
+}
+
+// NewMemoryStores returns empty stores that live in process memory.
+// Nothing is persisted, every call starts from a blank catalogue.
+func NewMemoryStores() Stores {
+	db := memory.NewDB()
+	return Stores{
+		Engine: memory.NewEngineStore(db),
+		Van:    memory.NewVanStore(db),
+		User:   memory.NewUserStore(db),
+		Token:  memory.NewTokenStore(db),
+		APIKey: memory.NewAPIKeyStore(db),
+
+		LoginAttempts: memory.NewLoginAttemptStore(),
+		LoginEvents:   memory.NewLoginEventStore(db),
+		RateLimiter:   ratelimit.NewMemoryLimiter(),
+	}
+}
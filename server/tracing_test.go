@@ -0,0 +1,76 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Record every span of the test in memory
+func captureSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		provider.Shutdown(t.Context())
+		otel.SetTracerProvider(previous)
+	})
+	return exporter
+}
+
+func TestTracingContinuesCallerTrace(t *testing.T) {
+	buf := captureLogs(t)
+	exporter := captureSpans(t)
+	api := newTestAPI(t)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/van/"+uuid.NewString(), nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	api.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	spans := exporter.GetSpans()
+	var server, service *tracetest.SpanStub
+	for i := range spans {
+		switch spans[i].Name {
+		case "GET /api/v1/van/{id}":
+			server = &spans[i]
+		case "VanService.GetVanById":
+			service = &spans[i]
+		}
+	}
+	if server == nil || service == nil {
+		t.Fatalf("missing server or service span, got %v", spans)
+	}
+	if server.SpanKind != trace.SpanKindServer || server.SpanContext.TraceID().String() != traceID {
+		t.Fatalf("server span should continue the caller's trace, got kind %v trace %s", server.SpanKind, server.SpanContext.TraceID())
+	}
+	if service.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Fatal("service span should be a child of the server span")
+	}
+
+	// the access log is written outside the server span but still joins to it
+	var joined bool
+	for _, record := range logRecords(t, buf) {
+		if record["msg"] == "request completed" {
+			joined = record["trace-id"] == traceID
+		}
+	}
+	if !joined {
+		t.Fatalf("access log line should carry the trace ID, logs: %s", buf.String())
+	}
+	if len(service.Events) == 0 {
+		t.Fatal("the not found error should be recorded on the service span")
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+)
+
+func TestRegisterAndLogin(t *testing.T) {
+	api := newTestAPI(t)
+	credentials := models.Credentials{Username: "driver", Password: "driver-password"}
+
+	resp := api.expect(http.StatusCreated, http.MethodPost, "/api/v1/register", "", credentials)
+	var users []models.UserRecord
+	api.data(resp, &users)
+	if users[0].Role != models.RoleViewer || users[0].PasswordHash != "" {
+		t.Fatalf("registered user should be a viewer without a password hash in the response: %+v", users[0])
+	}
+
+	api.expect(http.StatusConflict, http.MethodPost, "/api/v1/register", "", models.Credentials{Username: "DRIVER", Password: "other-password"})
+	api.expect(http.StatusBadRequest, http.MethodPost, "/api/v1/register", "", models.Credentials{Username: "x", Password: "driver-password"})
+	api.expect(http.StatusBadRequest, http.MethodPost, "/api/v1/register", "", models.Credentials{Username: "driver2", Password: "short"})
+
+	tokens := api.login("driver", "driver-password")
+	if tokens.Token == "" || tokens.RefreshToken == "" {
+		t.Fatalf("login returned %+v", tokens)
+	}
+
+	api.expect(http.StatusBadRequest, http.MethodPost, "/api/v1/login", "", models.Credentials{Username: "driver", Password: "wrong-password"})
+	api.expect(http.StatusBadRequest, http.MethodPost, "/api/v1/login", "", models.Credentials{Username: "nobody", Password: "driver-password"})
+	api.expect(http.StatusBadRequest, http.MethodPost, "/api/v1/login", "", `{"username":`)
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	api := newTestAPI(t)
+	first := api.login(testAdminUser, testAdminPassword)
+
+	resp := api.expect(http.StatusCreated, http.MethodPost, "/api/v1/token/refresh", "", models.RefreshRequest{RefreshToken: first.RefreshToken})
+	var rotated []testTokens
+	api.data(resp, &rotated)
+	if rotated[0].RefreshToken == first.RefreshToken {
+		t.Fatal("refresh should hand out a new refresh token")
+	}
+
+	// replaying the old token revokes the whole family, including the rotated one
+	api.expect(http.StatusUnauthorized, http.MethodPost, "/api/v1/token/refresh", "", models.RefreshRequest{RefreshToken: first.RefreshToken})
+	api.expect(http.StatusUnauthorized, http.MethodPost, "/api/v1/token/refresh", "", models.RefreshRequest{RefreshToken: rotated[0].RefreshToken})
+
+	api.expect(http.StatusUnauthorized, http.MethodPost, "/api/v1/token/refresh", "", models.RefreshRequest{RefreshToken: "unknown"})
+	api.expect(http.StatusBadRequest, http.MethodPost, "/api/v1/token/refresh", "", models.RefreshRequest{})
+}
+
+func TestLogoutRevokesTokens(t *testing.T) {
+	api := newTestAPI(t)
+	tokens := api.login(testAdminUser, testAdminPassword)
+
+	api.expect(http.StatusNoContent, http.MethodPost, "/api/v1/logout", tokens.Token, models.RefreshRequest{RefreshToken: tokens.RefreshToken})
+
+	api.expect(http.StatusUnauthorized, http.MethodPost, "/api/v1/engine", tokens.Token, testEngineBody())
+	api.expect(http.StatusUnauthorized, http.MethodPost, "/api/v1/token/refresh", "", models.RefreshRequest{RefreshToken: tokens.RefreshToken})
+}
+
+func TestLogoutKeepsOtherUsersRefreshTokens(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.login(testAdminUser, testAdminPassword)
+	viewer := api.viewerToken("driver")
+
+	// the access token is still revoked, the refresh token naming another user is ignored
+	api.expect(http.StatusNoContent, http.MethodPost, "/api/v1/logout", viewer, models.RefreshRequest{RefreshToken: admin.RefreshToken})
+	api.expect(http.StatusCreated, http.MethodPost, "/api/v1/token/refresh", "", models.RefreshRequest{RefreshToken: admin.RefreshToken})
+}
+
+func TestChangePassword(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.viewerToken("driver")
+	other := api.login("driver", "viewer-password")
+
+	api.expect(http.StatusBadRequest, http.MethodPut, "/api/v1/user/password", token, models.PasswordChange{CurrentPassword: "wrong-password", NewPassword: "new-password"})
+	api.expect(http.StatusBadRequest, http.MethodPut, "/api/v1/user/password", token, models.PasswordChange{CurrentPassword: "viewer-password", NewPassword: "short"})
+	api.expect(http.StatusOK, http.MethodPut, "/api/v1/user/password", token, models.PasswordChange{CurrentPassword: "viewer-password", NewPassword: "new-password"})
+
+	// the access token used for the change is revoked with the rest of the session
+	api.expect(http.StatusUnauthorized, http.MethodPut, "/api/v1/user/password", token, models.PasswordChange{CurrentPassword: "new-password", NewPassword: "newer-password"})
+
+	api.expect(http.StatusBadRequest, http.MethodPost, "/api/v1/login", "", models.Credentials{Username: "driver", Password: "viewer-password"})
+	api.login("driver", "new-password")
+
+	// sessions started with the old password cannot be refreshed
+	api.expect(http.StatusUnauthorized, http.MethodPost, "/api/v1/token/refresh", "", models.RefreshRequest{RefreshToken: other.RefreshToken})
+}
+
+func TestSetRole(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.adminToken()
+
+	resp := api.expect(http.StatusCreated, http.MethodPost, "/api/v1/register", "", models.Credentials{Username: "manager", Password: "manager-password"})
+	var users []models.UserRecord
+	api.data(resp, &users)
+	path := "/api/v1/user/" + users[0].ID.String() + "/role"
+
+	viewer := api.login("manager", "manager-password").Token
+	api.expect(http.StatusForbidden, http.MethodPatch, path, viewer, models.RoleChange{Role: models.RoleAdmin})
+	api.expect(http.StatusForbidden, http.MethodPost, "/api/v1/engine", viewer, testEngineBody())
+
+	api.expect(http.StatusOK, http.MethodPatch, path, admin, models.RoleChange{Role: models.RoleFleetManager})
+	api.expect(http.StatusBadRequest, http.MethodPatch, path, admin, models.RoleChange{Role: "owner"})
+	api.expect(http.StatusNotFound, http.MethodPatch, "/api/v1/user/"+uuid.NewString()+"/role", admin, models.RoleChange{Role: models.RoleViewer})
+
+	// the role is read from the token, so it applies from the next login
+	manager := api.login("manager", "manager-password").Token
+	engine := api.createEngine(manager, testEngineBody())
+	api.expect(http.StatusForbidden, http.MethodDelete, "/api/v1/engine/"+engine.ID.String(), manager, nil)
+}
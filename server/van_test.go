@@ -0,0 +1,207 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+)
+
+func TestVanCreateAndGet(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.adminToken()
+	engine := api.createEngine(token, testEngineBody())
+
+	rec := api.do(http.MethodPost, "/api/v1/van", token, testVanBody("Atlas", engine.ID, 5000))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d, body: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	if !strings.HasPrefix(rec.Header().Get("Location"), "/api/v1/van/") {
+		t.Fatalf("unexpected Location header %q", rec.Header().Get("Location"))
+	}
+
+	van := api.createVan(token, testVanBody("Boreas", engine.ID, 7000))
+	if van.ID == uuid.Nil || van.EngineID != engine.ID || van.CreatedAt.IsZero() {
+		t.Fatalf("unexpected created van: %+v", van)
+	}
+
+	resp := api.expect(http.StatusOK, http.MethodGet, "/api/v1/van/"+van.ID.String(), "", nil)
+	var vans []models.VanRecord
+	api.data(resp, &vans)
+	if len(vans) != 1 || vans[0].Name != "Boreas" {
+		t.Fatalf("got %+v, want van Boreas", vans)
+	}
+}
+
+func TestVanCreateValidation(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.adminToken()
+	engine := api.createEngine(token, testEngineBody())
+
+	withField := func(key string, value interface{}) map[string]interface{} {
+		body := testVanBody("Atlas", engine.ID, 5000)
+		body[key] = value
+		return body
+	}
+	missingName := testVanBody("Atlas", engine.ID, 5000)
+	delete(missingName, "name")
+
+	tests := []struct {
+		name string
+		body interface{}
+	}{
+		{"malformed json", `{"name":`},
+		{"missing name", missingName},
+		{"wrong value type", withField("price", "cheap")},
+		{"unknown category", withField("category", "spaceship")},
+		{"unknown fuel type", withField("fuel-type", "steam")},
+		{"negative price", withField("price", -1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api.t = t
+			api.expect(http.StatusBadRequest, http.MethodPost, "/api/v1/van", token, tt.body)
+		})
+	}
+
+	// passes request validation but references an engine that does not exist
+	api.t = t
+	resp := api.expect(http.StatusUnprocessableEntity, http.MethodPost, "/api/v1/van", token, testVanBody("Ghost", uuid.New(), 5000))
+	if resp.Message == "" {
+		t.Fatal("foreign key error should carry a message")
+	}
+}
+
+func TestVanNotFound(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.adminToken()
+	engine := api.createEngine(token, testEngineBody())
+	missing := "/api/v1/van/" + uuid.NewString()
+
+	api.expect(http.StatusNotFound, http.MethodGet, missing, "", nil)
+	api.expect(http.StatusNotFound, http.MethodPut, missing, token, testVanBody("Atlas", engine.ID, 5000))
+	api.expect(http.StatusNotFound, http.MethodPatch, missing, token, map[string]interface{}{"price": 100})
+	api.expect(http.StatusNotFound, http.MethodDelete, missing, token, nil)
+
+	api.expect(http.StatusBadRequest, http.MethodGet, "/api/v1/van/not-a-uuid", "", nil)
+}
+
+func TestVanListFilterAndPaging(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.adminToken()
+	engine := api.createEngine(token, testEngineBody())
+
+	prices := map[string]int64{"Atlas": 3000, "Boreas": 9000, "Cirrus": 6000, "Dorado": 12000, "Eos": 4500}
+	for name, price := range prices {
+		api.createVan(token, testVanBody(name, engine.ID, price))
+	}
+
+	resp := api.expect(http.StatusOK, http.MethodGet, "/api/v1/vans?min-price=4000&max-price=10000&sort=price", "", nil)
+	var vans []models.VanRecord
+	api.data(resp, &vans)
+	if resp.Meta.Total != 3 || len(vans) != 3 || vans[0].Name != "Eos" || vans[2].Name != "Boreas" {
+		t.Fatalf("price filter returned %+v, meta %+v", vans, resp.Meta)
+	}
+
+	// page through by name with a cursor, two at a time
+	var names []string
+	path := "/api/v1/vans?sort=name&limit=2"
+	for path != "" {
+		resp = api.expect(http.StatusOK, http.MethodGet, path, "", nil)
+		api.data(resp, &vans)
+		for _, van := range vans {
+			names = append(names, van.Name)
+		}
+		path = ""
+		if resp.Meta.NextCursor != "" {
+			path = "/api/v1/vans?sort=name&limit=2&cursor=" + resp.Meta.NextCursor
+		}
+	}
+	if strings.Join(names, ",") != "Atlas,Boreas,Cirrus,Dorado,Eos" {
+		t.Fatalf("cursor paging returned %v", names)
+	}
+
+	resp = api.expect(http.StatusOK, http.MethodGet, "/api/v1/vans?sort=name&limit=2&offset=4", "", nil)
+	api.data(resp, &vans)
+	if len(vans) != 1 || vans[0].Name != "Eos" {
+		t.Fatalf("offset paging returned %+v", vans)
+	}
+
+	api.expect(http.StatusBadRequest, http.MethodGet, "/api/v1/vans?category=spaceship", "", nil)
+	api.expect(http.StatusBadRequest, http.MethodGet, "/api/v1/vans?min-price=900&max-price=100", "", nil)
+	api.expect(http.StatusBadRequest, http.MethodGet, "/api/v1/vans?cursor=garbage", "", nil)
+
+	// well formed cursors whose value does not fit the sort column
+	for _, cursor := range []models.Cursor{
+		{Sort: "price", Order: "asc", Value: "cheap", ID: uuid.New()},
+		{Sort: "created_at", Order: "asc", Value: "yesterday", ID: uuid.New()},
+		{Sort: "name", Order: "asc", Value: "Eos\x00", ID: uuid.New()},
+	} {
+		api.expect(http.StatusBadRequest, http.MethodGet, "/api/v1/vans?sort="+cursor.Sort+"&cursor="+cursor.Encode(), "", nil)
+	}
+}
+
+func TestVanSearch(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.adminToken()
+	engine := api.createEngine(token, testEngineBody())
+	api.createVan(token, testVanBody("Atlas", engine.ID, 3000))
+	api.createVan(token, testVanBody("Boreas", engine.ID, 9000))
+
+	resp := api.expect(http.StatusOK, http.MethodGet, "/api/v1/vans/search?q=boreas", "", nil)
+	var results []models.VanSearchResult
+	api.data(resp, &results)
+	if len(results) != 1 || results[0].Name != "Boreas" || !strings.Contains(results[0].Snippet, "<mark>Boreas</mark>") {
+		t.Fatalf("search returned %+v", results)
+	}
+
+	resp = api.expect(http.StatusOK, http.MethodGet, "/api/v1/vans/search?q=camper+-atlas", "", nil)
+	api.data(resp, &results)
+	if len(results) != 1 || results[0].Name != "Boreas" {
+		t.Fatalf("excluded term still matched: %+v", results)
+	}
+
+	api.expect(http.StatusBadRequest, http.MethodGet, "/api/v1/vans/search", "", nil)
+}
+
+func TestVanUpdateAndDelete(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.adminToken()
+	engine := api.createEngine(token, testEngineBody())
+	van := api.createVan(token, testVanBody("Atlas", engine.ID, 5000))
+	path := "/api/v1/van/" + van.ID.String()
+
+	resp := api.expect(http.StatusOK, http.MethodPut, path, token, testVanBody("Atlas II", engine.ID, 5500))
+	var vans []models.VanRecord
+	api.data(resp, &vans)
+	if vans[0].Name != "Atlas II" || vans[0].Price != 5500 {
+		t.Fatalf("PUT did not update the van: %+v", vans[0])
+	}
+
+	resp = api.expect(http.StatusOK, http.MethodPatch, path, token, map[string]interface{}{"price": 6000})
+	api.data(resp, &vans)
+	if vans[0].Price != 6000 || vans[0].Name != "Atlas II" {
+		t.Fatalf("PATCH should only change the price: %+v", vans[0])
+	}
+
+	api.expect(http.StatusBadRequest, http.MethodPatch, path, token, map[string]interface{}{"category": "spaceship"})
+	api.expect(http.StatusUnprocessableEntity, http.MethodPatch, path, token, map[string]interface{}{"engine-id": uuid.New()})
+
+	api.expect(http.StatusNoContent, http.MethodDelete, path, token, nil)
+	api.expect(http.StatusNotFound, http.MethodGet, path, "", nil)
+}
+
+func TestVanWritePermissions(t *testing.T) {
+	api := newTestAPI(t)
+	admin := api.adminToken()
+	engine := api.createEngine(admin, testEngineBody())
+	van := api.createVan(admin, testVanBody("Atlas", engine.ID, 5000))
+	viewer := api.viewerToken("viewer")
+
+	api.expect(http.StatusForbidden, http.MethodPost, "/api/v1/van", viewer, testVanBody("Boreas", engine.ID, 5000))
+	api.expect(http.StatusForbidden, http.MethodPatch, "/api/v1/van/"+van.ID.String(), viewer, map[string]interface{}{"price": 1})
+	api.expect(http.StatusForbidden, http.MethodDelete, "/api/v1/van/"+van.ID.String(), viewer, nil)
+}
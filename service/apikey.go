@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+	"github.com/harshitrajsinha/goserver-vanmango/store"
+)
+
+// APIKeyPrefix starts every API key, so keys are easy to spot in
+// secret scanners and cannot be mistaken for a JWT
+const APIKeyPrefix = "vm_"
+
+// Characters of the key kept in clear to tell keys apart, prefix included
+const apiKeyDisplayLength = 11
+
+// Last-used times are written at most this often per key, not on every request
+const apiKeyLastUsedResolution = time.Minute
+
+var ErrInvalidAPIKey = errors.New("api key is invalid, expired or revoked")
+
+type APIKeyService struct {
+	store store.APIKeyStoreInterface
+}
+
+func NewAPIKeyService(store store.APIKeyStoreInterface) *APIKeyService {
+	return &APIKeyService{
+		store: store,
+	}
+}
+
+// Only the hash is persisted, a leaked table cannot be used to call the API
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKey mints a key for the user createdBy. The plain key is returned
+// once and cannot be recovered afterwards.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, request *models.APIKey, createdBy string) (*models.APIKeyCreated, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	key := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	record, err := s.store.CreateAPIKey(ctx, request, key[:apiKeyDisplayLength], hashAPIKey(key), createdBy)
+	if err != nil {
+		return nil, err
+	}
+	return &models.APIKeyCreated{APIKeyRecord: *record, Key: key}, nil
+}
+
+func (s *APIKeyService) GetAllAPIKey(ctx context.Context) ([]models.APIKeyRecord, error) {
+	return s.store.GetAllAPIKey(ctx)
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id string) (int64, error) {
+	return s.store.RevokeAPIKey(ctx, id)
+}
+
+// AuthenticateAPIKey returns the live key matching key, or ErrInvalidAPIKey
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, key string) (*models.APIKeyRecord, error) {
+
+	record, err := s.store.GetAPIKeyByHash(ctx, hashAPIKey(key))
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	current := time.Now()
+	if record.RevokedAt != nil || (record.ExpiresAt != nil && !record.ExpiresAt.After(current)) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	// a failed write only loses usage tracking, the request can still go through
+	if record.LastUsedAt == nil || current.Sub(*record.LastUsedAt) >= apiKeyLastUsedResolution {
+		if err := s.store.UpdateAPIKeyLastUsed(ctx, record.ID.String(), current); err != nil {
+			logging.FromContext(ctx).Warn("Recording API key use failed", "api-key-id", record.ID, "error", err)
+		} else {
+			record.LastUsedAt = &current
+		}
+	}
+	return record, nil
+}
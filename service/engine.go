@@ -5,6 +5,9 @@ import (
 
 	"github.com/harshitrajsinha/goserver-vanmango/models"
 	"github.com/harshitrajsinha/goserver-vanmango/store"
+	"github.com/harshitrajsinha/goserver-vanmango/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type EngineService struct {
@@ -17,45 +20,62 @@ func NewEngineService(store store.EngineStoreInterface) *EngineService {
 	}
 }
 
-func (s *EngineService) GetEngineByID(ctx context.Context, id string) (interface{}, error) {
+func (s *EngineService) GetEngineByID(ctx context.Context, id string) (*models.EngineRecord, error) {
+	ctx, span := tracing.Start(ctx, "EngineService.GetEngineByID", trace.WithAttributes(attribute.String("engine.id", id)))
+	defer span.End()
+
 	engine, err := s.store.GetEngineById(ctx, id)
 	if err != nil {
+		tracing.RecordError(span, err)
 		return nil, err
 	}
-	return &engine, nil
+	return engine, nil
 }
 
-func (s *EngineService) GetAllEngine(ctx context.Context) (interface{}, error) {
-	engine, err := s.store.GetAllEngine(ctx)
+func (s *EngineService) GetAllEngine(ctx context.Context, filter *models.EngineFilter) ([]models.EngineRecord, *models.PageInfo, error) {
+	ctx, span := tracing.Start(ctx, "EngineService.GetAllEngine")
+	defer span.End()
+
+	engine, pageInfo, err := s.store.GetAllEngine(ctx, filter)
 	if err != nil {
-		return nil, err
+		tracing.RecordError(span, err)
+		return nil, nil, err
 	}
-	return &engine, nil
+	return engine, pageInfo, nil
 }
 
-func (s *EngineService) CreateEngine(ctx context.Context, engineReq *models.Engine) (int64, error) {
+func (s *EngineService) CreateEngine(ctx context.Context, engineReq *models.Engine) (*models.EngineRecord, error) {
+	ctx, span := tracing.Start(ctx, "EngineService.CreateEngine")
+	defer span.End()
 
 	createdEngine, err := s.store.CreateEngine(ctx, engineReq)
 	if err != nil {
-		return -1, err
+		tracing.RecordError(span, err)
+		return nil, err
 	}
 
 	return createdEngine, nil
 }
 
 func (s *EngineService) UpdateEngine(ctx context.Context, id string, engineReq *models.Engine) (int64, error) {
+	ctx, span := tracing.Start(ctx, "EngineService.UpdateEngine", trace.WithAttributes(attribute.String("engine.id", id)))
+	defer span.End()
 
 	updatedEngine, err := s.store.UpdateEngine(ctx, id, engineReq)
 	if err != nil {
+		tracing.RecordError(span, err)
 		return -1, err
 	}
 	return updatedEngine, nil
 }
 
 func (s *EngineService) DeleteEngine(ctx context.Context, id string) (int64, error) {
+	ctx, span := tracing.Start(ctx, "EngineService.DeleteEngine", trace.WithAttributes(attribute.String("engine.id", id)))
+	defer span.End()
 
 	deletedEngine, err := s.store.DeleteEngine(ctx, id)
 	if err != nil {
+		tracing.RecordError(span, err)
 		return -1, err
 	}
 	return deletedEngine, nil
@@ -2,22 +2,53 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/harshitrajsinha/goserver-vanmango/models"
 )
 
 type EngineServiceInterface interface {
-	GetEngineByID(ctx context.Context, id string) (interface{}, error)
-	GetAllEngine(ctx context.Context) (interface{}, error)
-	CreateEngine(ctx context.Context, engineReq *models.Engine) (int64, error)
+	GetEngineByID(ctx context.Context, id string) (*models.EngineRecord, error)
+	GetAllEngine(ctx context.Context, filter *models.EngineFilter) ([]models.EngineRecord, *models.PageInfo, error)
+	CreateEngine(ctx context.Context, engineReq *models.Engine) (*models.EngineRecord, error)
 	UpdateEngine(ctx context.Context, id string, engineReq *models.Engine) (int64, error)
 	DeleteEngine(ctx context.Context, id string) (int64, error)
 }
 
 type VanServiceInterface interface {
-	GetVanById(ctx context.Context, id string) (interface{}, error)
-	GetAllVan(ctx context.Context) (interface{}, error)
-	CreateVan(ctx context.Context, vanReq *models.Van) (int64, error)
+	GetVanById(ctx context.Context, id string) (*models.VanRecord, error)
+	GetAllVan(ctx context.Context, filter *models.VanFilter) ([]models.VanRecord, *models.PageInfo, error)
+	SearchVan(ctx context.Context, search *models.VanSearch) ([]models.VanSearchResult, *models.PageInfo, error)
+	CreateVan(ctx context.Context, vanReq *models.Van) (*models.VanRecord, error)
 	UpdateVan(ctx context.Context, vanID string, vanReq *models.Van) (int64, error)
 	DeleteVan(ctx context.Context, id string) (int64, error)
 }
+
+type UserServiceInterface interface {
+	Register(ctx context.Context, credentials *models.Credentials) (*models.UserRecord, error)
+	Authenticate(ctx context.Context, credentials *models.Credentials) (*models.UserRecord, error)
+	ChangePassword(ctx context.Context, userID string, request *models.PasswordChange) error
+	SetRole(ctx context.Context, userID string, role models.Role) (*models.UserRecord, error)
+}
+
+type TokenServiceInterface interface {
+	IssueRefreshToken(ctx context.Context, user *models.UserRecord) (string, error)
+	RotateRefreshToken(ctx context.Context, refreshToken string) (*models.UserRecord, string, error)
+	RevokeRefreshToken(ctx context.Context, userID string, refreshToken string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID string) error
+	RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+type APIKeyServiceInterface interface {
+	CreateAPIKey(ctx context.Context, request *models.APIKey, createdBy string) (*models.APIKeyCreated, error)
+	GetAllAPIKey(ctx context.Context) ([]models.APIKeyRecord, error)
+	RevokeAPIKey(ctx context.Context, id string) (int64, error)
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.APIKeyRecord, error)
+}
+
+type LoginGuardInterface interface {
+	Check(ctx context.Context, username string, ip string) error
+	RecordFailure(ctx context.Context, username string, ip string) error
+	RecordSuccess(ctx context.Context, user *models.UserRecord, ip string) error
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/harshitrajsinha/goserver-vanmango/config"
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+	"github.com/harshitrajsinha/goserver-vanmango/store"
+)
+
+// Usernames longer than any valid account are cut before they are stored or logged
+const maxAuditedUsernameLength = 64
+
+// LoginLockedError is returned while a username or client address is locked out
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, retry after %s", e.RetryAfter)
+}
+
+// LoginGuard tracks failed logins per username and per client address, locks
+// them out with a growing delay, and keeps an audit record of every attempt
+type LoginGuard struct {
+	attempts store.LoginAttemptStoreInterface
+	events   store.LoginEventStoreInterface
+	lockout  config.LockoutConfig
+}
+
+func NewLoginGuard(attempts store.LoginAttemptStoreInterface, events store.LoginEventStoreInterface, lockout config.LockoutConfig) *LoginGuard {
+	return &LoginGuard{
+		attempts: attempts,
+		events:   events,
+		lockout:  lockout,
+	}
+}
+
+// Usernames match case-insensitively, so do their counters
+func usernameKey(username string) string {
+	return "username:" + strings.ToLower(auditedUsername(username))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+func auditedUsername(username string) string {
+	if runes := []rune(username); len(runes) > maxAuditedUsernameLength {
+		return string(runes[:maxAuditedUsernameLength])
+	}
+	return username
+}
+
+// Lockout after the given number of failures past the limit: the base duration,
+// doubled for every further failure, capped at the maximum
+func (g *LoginGuard) lockoutFor(excess int) time.Duration {
+	lockout := g.lockout.Duration
+	for i := 0; i < excess && lockout < g.lockout.MaxDuration; i++ {
+		lockout *= 2
+	}
+	return min(lockout, g.lockout.MaxDuration)
+}
+
+// Check returns a *LoginLockedError when the username or the client address is locked out
+func (g *LoginGuard) Check(ctx context.Context, username string, ip string) error {
+	var lockedUntil time.Time
+	for _, key := range []string{usernameKey(username), ipKey(ip)} {
+		attempts, err := g.attempts.GetLoginAttempts(ctx, key)
+		if err != nil {
+			return err
+		}
+		if attempts.LockedUntil.After(lockedUntil) {
+			lockedUntil = attempts.LockedUntil
+		}
+	}
+
+	if retryAfter := time.Until(lockedUntil); retryAfter > 0 {
+		// blocked attempts are only logged, an attacker cannot grow the audit table with them
+		logging.FromContext(ctx).Warn("Login blocked by lockout", "username", auditedUsername(username), "ip", ip, "retry-after", retryAfter)
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login against the username and the client address
+// and locks out whichever has reached its limit
+func (g *LoginGuard) RecordFailure(ctx context.Context, username string, ip string) error {
+	logger := logging.FromContext(ctx)
+
+	limits := []struct {
+		key         string
+		maxAttempts int
+	}{
+		{usernameKey(username), g.lockout.MaxAttempts},
+		{ipKey(ip), g.lockout.MaxAttemptsPerIP},
+	}
+	for _, limit := range limits {
+		// counters start over once the longest lockout has passed without failures
+		attempts, err := g.attempts.RecordLoginFailure(ctx, limit.key, g.lockout.MaxDuration)
+		if err != nil {
+			return err
+		}
+		if attempts.Failures < limit.maxAttempts {
+			continue
+		}
+		lockout := g.lockoutFor(attempts.Failures - limit.maxAttempts)
+		if err := g.attempts.LockLogin(ctx, limit.key, time.Now().Add(lockout)); err != nil {
+			return err
+		}
+		logger.Warn("Login locked out", "key", limit.key, "failures", attempts.Failures, "lockout", lockout)
+	}
+
+	return g.audit(ctx, &models.LoginEvent{Username: auditedUsername(username), IP: ip, Outcome: models.LoginFailed})
+}
+
+// RecordSuccess clears the failures of the username. The client address keeps its
+// count, so signing in to one account does not reset guessing at others.
+func (g *LoginGuard) RecordSuccess(ctx context.Context, user *models.UserRecord, ip string) error {
+	if err := g.attempts.ClearLoginAttempts(ctx, usernameKey(user.Username)); err != nil {
+		return err
+	}
+	return g.audit(ctx, &models.LoginEvent{Username: user.Username, UserID: &user.ID, IP: ip, Outcome: models.LoginSucceeded})
+}
+
+func (g *LoginGuard) audit(ctx context.Context, event *models.LoginEvent) error {
+	logging.FromContext(ctx).Info("Login attempt", "username", event.Username, "ip", event.IP, "outcome", event.Outcome)
+	return g.events.CreateLoginEvent(ctx, event)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+	"github.com/harshitrajsinha/goserver-vanmango/store"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used")
+)
+
+type TokenService struct {
+	store     store.TokenStoreInterface
+	userStore store.UserStoreInterface
+	// lifetime of a refresh token, each refresh hands out a new one
+	refreshTokenTTL time.Duration
+}
+
+func NewTokenService(store store.TokenStoreInterface, userStore store.UserStoreInterface, refreshTokenTTL time.Duration) *TokenService {
+	return &TokenService{
+		store:           store,
+		userStore:       userStore,
+		refreshTokenTTL: refreshTokenTTL,
+	}
+}
+
+// Only the hash is persisted, a leaked table cannot be replayed
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *TokenService) createRefreshToken(ctx context.Context, userID uuid.UUID, familyID uuid.UUID) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+
+	err := s.store.CreateRefreshToken(ctx, userID, familyID, hashRefreshToken(token), time.Now().Add(s.refreshTokenTTL))
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// IssueRefreshToken starts a new token family for a fresh login
+func (s *TokenService) IssueRefreshToken(ctx context.Context, user *models.UserRecord) (string, error) {
+	return s.createRefreshToken(ctx, user.ID, uuid.New())
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one in the same family.
+// Presenting a token that was already exchanged revokes the whole family, since
+// either the client or an attacker is holding a stolen copy.
+func (s *TokenService) RotateRefreshToken(ctx context.Context, refreshToken string) (*models.UserRecord, string, error) {
+	tokenHash := hashRefreshToken(refreshToken)
+
+	current, err := s.store.UseRefreshToken(ctx, tokenHash)
+	if errors.Is(err, store.ErrNotFound) {
+		existing, err := s.store.GetRefreshToken(ctx, tokenHash)
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, "", ErrInvalidRefreshToken
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if existing.UsedAt != nil && existing.RevokedAt == nil {
+			if _, err := s.store.RevokeRefreshFamily(ctx, existing.FamilyID); err != nil {
+				return nil, "", err
+			}
+			logging.FromContext(ctx).Warn("Refresh token reuse detected, token family revoked", "user", existing.UserID, "family-id", existing.FamilyID)
+			return nil, "", ErrRefreshTokenReused
+		}
+		return nil, "", ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	// reload the user so role changes and deleted accounts take effect on refresh
+	user, err := s.userStore.GetUserByID(ctx, current.UserID.String())
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, "", ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	newToken, err := s.createRefreshToken(ctx, current.UserID, current.FamilyID)
+	if err != nil {
+		return nil, "", err
+	}
+	return user, newToken, nil
+}
+
+// RevokeRefreshToken ends the token family the refresh token belongs to. Only the
+// user the token was issued to may end it, anyone else is told it is invalid.
+func (s *TokenService) RevokeRefreshToken(ctx context.Context, userID string, refreshToken string) error {
+	existing, err := s.store.GetRefreshToken(ctx, hashRefreshToken(refreshToken))
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return err
+	}
+	if existing.UserID.String() != userID {
+		logging.FromContext(ctx).Warn("Refresh token presented by another user", "token-user", existing.UserID, "family-id", existing.FamilyID)
+		return ErrInvalidRefreshToken
+	}
+	_, err = s.store.RevokeRefreshFamily(ctx, existing.FamilyID)
+	return err
+}
+
+// RevokeUserRefreshTokens ends every token family of the user, so no session
+// outlives a password change
+func (s *TokenService) RevokeUserRefreshTokens(ctx context.Context, userID string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return err
+	}
+	revoked, err := s.store.RevokeUserRefreshTokens(ctx, id)
+	if err != nil {
+		return err
+	}
+	logging.FromContext(ctx).Debug("Refresh tokens revoked", "count", revoked)
+	return nil
+}
+
+// RevokeAccessToken denylists an access token ID until the token expires
+func (s *TokenService) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	return s.store.RevokeAccessToken(ctx, jti, expiresAt)
+}
+
+func (s *TokenService) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	return s.store.IsAccessTokenRevoked(ctx, jti)
+}
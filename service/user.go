@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+	"github.com/harshitrajsinha/goserver-vanmango/store"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("incorrect username or password")
+	ErrUsernameTaken      = &store.Error{Kind: store.ErrConflict, Message: "username already exists"}
+)
+
+// Compared against when the username is unknown so that both paths cost one bcrypt comparison
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("vanmango-dummy-password"), bcrypt.DefaultCost)
+
+type UserService struct {
+	store store.UserStoreInterface
+}
+
+func NewUserService(store store.UserStoreInterface) *UserService {
+	return &UserService{
+		store: store,
+	}
+}
+
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Register creates a read-only account, roles are granted by an admin afterwards
+func (s *UserService) Register(ctx context.Context, credentials *models.Credentials) (*models.UserRecord, error) {
+	return s.createUser(ctx, credentials, models.RoleViewer)
+}
+
+func (s *UserService) createUser(ctx context.Context, credentials *models.Credentials, role models.Role) (*models.UserRecord, error) {
+
+	passwordHash, err := hashPassword(credentials.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.store.CreateUser(ctx, credentials.Username, passwordHash, role)
+	if errors.Is(err, store.ErrConflict) {
+		return nil, ErrUsernameTaken
+	}
+	return user, err
+}
+
+func (s *UserService) Authenticate(ctx context.Context, credentials *models.Credentials) (*models.UserRecord, error) {
+
+	user, err := s.store.GetUserByUsername(ctx, credentials.Username)
+	if errors.Is(err, store.ErrNotFound) {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(credentials.Password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(credentials.Password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+func (s *UserService) ChangePassword(ctx context.Context, userID string, request *models.PasswordChange) error {
+
+	user, err := s.store.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(request.CurrentPassword)); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	passwordHash, err := hashPassword(request.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.store.UpdatePassword(ctx, userID, passwordHash)
+	return err
+}
+
+func (s *UserService) SetRole(ctx context.Context, userID string, role models.Role) (*models.UserRecord, error) {
+
+	if _, err := s.store.UpdateRole(ctx, userID, role); err != nil {
+		return nil, err
+	}
+	return s.store.GetUserByID(ctx, userID)
+}
+
+// EnsureUser creates the account with the given role if the username is free,
+// otherwise it makes sure the existing account holds that role.
+// Used at startup to bootstrap the admin account from AUTH_USER/AUTH_PASS.
+func (s *UserService) EnsureUser(ctx context.Context, credentials *models.Credentials, role models.Role) (bool, error) {
+	_, err := s.createUser(ctx, credentials, role)
+	if !errors.Is(err, ErrUsernameTaken) {
+		return err == nil, err
+	}
+
+	existing, err := s.store.GetUserByUsername(ctx, credentials.Username)
+	if err != nil || existing.Role == role {
+		return false, err
+	}
+	_, err = s.store.UpdateRole(ctx, existing.ID.String(), role)
+	return false, err
+}
@@ -5,6 +5,9 @@ import (
 
 	"github.com/harshitrajsinha/goserver-vanmango/models"
 	"github.com/harshitrajsinha/goserver-vanmango/store"
+	"github.com/harshitrajsinha/goserver-vanmango/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type VanService struct {
@@ -17,45 +20,74 @@ func NewVanService(store store.VanStoreInterface) *VanService {
 	}
 }
 
-func (v *VanService) GetVanById(ctx context.Context, id string) (interface{}, error) {
+func (v *VanService) GetVanById(ctx context.Context, id string) (*models.VanRecord, error) {
+	ctx, span := tracing.Start(ctx, "VanService.GetVanById", trace.WithAttributes(attribute.String("van.id", id)))
+	defer span.End()
+
 	van, err := v.store.GetVanById(ctx, id)
 	if err != nil {
+		tracing.RecordError(span, err)
 		return nil, err
 	}
-	return &van, nil
+	return van, nil
 }
 
-func (v *VanService) GetAllVan(ctx context.Context) (interface{}, error) {
-	van, err := v.store.GetAllVan(ctx)
+func (v *VanService) GetAllVan(ctx context.Context, filter *models.VanFilter) ([]models.VanRecord, *models.PageInfo, error) {
+	ctx, span := tracing.Start(ctx, "VanService.GetAllVan")
+	defer span.End()
+
+	van, pageInfo, err := v.store.GetAllVan(ctx, filter)
 	if err != nil {
-		return nil, err
+		tracing.RecordError(span, err)
+		return nil, nil, err
+	}
+	return van, pageInfo, nil
+}
+
+func (v *VanService) SearchVan(ctx context.Context, search *models.VanSearch) ([]models.VanSearchResult, *models.PageInfo, error) {
+	ctx, span := tracing.Start(ctx, "VanService.SearchVan")
+	defer span.End()
+
+	van, pageInfo, err := v.store.SearchVan(ctx, search)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, nil, err
 	}
-	return &van, nil
+	return van, pageInfo, nil
 }
 
-func (v *VanService) CreateVan(ctx context.Context, vanReq *models.Van) (int64, error) {
+func (v *VanService) CreateVan(ctx context.Context, vanReq *models.Van) (*models.VanRecord, error) {
+	ctx, span := tracing.Start(ctx, "VanService.CreateVan")
+	defer span.End()
 
 	createdVan, err := v.store.CreateVan(ctx, vanReq)
 	if err != nil {
-		return -1, err
+		tracing.RecordError(span, err)
+		return nil, err
 	}
 
 	return createdVan, nil
 }
 
 func (v *VanService) UpdateVan(ctx context.Context, id string, vanReq *models.Van) (int64, error) {
+	ctx, span := tracing.Start(ctx, "VanService.UpdateVan", trace.WithAttributes(attribute.String("van.id", id)))
+	defer span.End()
 
 	updatedVan, err := v.store.UpdateVan(ctx, id, vanReq)
 	if err != nil {
+		tracing.RecordError(span, err)
 		return -1, err
 	}
 	return updatedVan, nil
 }
 
 func (v *VanService) DeleteVan(ctx context.Context, id string) (int64, error) {
+	ctx, span := tracing.Start(ctx, "VanService.DeleteVan", trace.WithAttributes(attribute.String("van.id", id)))
+	defer span.End()
 
 	deletedVan, err := v.store.DeleteVan(ctx, id)
 	if err != nil {
+		tracing.RecordError(span, err)
 		return -1, err
 	}
 	return deletedVan, nil
@@ -0,0 +1,126 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+	"github.com/lib/pq"
+)
+
+// Column list matching the field order scanned by scanAPIKey
+const apiKeyColumns = "id, name, key_prefix, scopes, created_by, expires_at, last_used_at, revoked_at, created_at"
+
+type APIKeyStore struct {
+	db tracedDB
+}
+
+// Constructor method for db variable
+func NewAPIKeyStore(db *sql.DB) *APIKeyStore {
+	return &APIKeyStore{db: tracedDB{DB: db}}
+}
+
+func scanAPIKey(row rowScanner) (*models.APIKeyRecord, error) {
+	var apiKey models.APIKeyRecord
+	var scopes []string
+	var createdBy uuid.NullUUID
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(&apiKey.ID, &apiKey.Name, &apiKey.Prefix, pq.Array(&scopes), &createdBy, &expiresAt, &lastUsedAt, &revokedAt, &apiKey.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	apiKey.Scopes = make([]models.Permission, 0, len(scopes))
+	for _, scope := range scopes {
+		apiKey.Scopes = append(apiKey.Scopes, models.Permission(scope))
+	}
+	if createdBy.Valid {
+		apiKey.CreatedBy = &createdBy.UUID
+	}
+	if expiresAt.Valid {
+		apiKey.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		apiKey.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		apiKey.RevokedAt = &revokedAt.Time
+	}
+	return &apiKey, nil
+}
+
+func (a APIKeyStore) CreateAPIKey(ctx context.Context, apiKey *models.APIKey, prefix string, keyHash string, createdBy string) (*models.APIKeyRecord, error) {
+
+	scopes := make([]string, 0, len(apiKey.Scopes))
+	for _, scope := range apiKey.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	var expiresAt sql.NullTime
+	if apiKey.ExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: apiKey.ExpiresAt.UTC(), Valid: true}
+	}
+
+	var query string = "INSERT INTO api_keys (name, key_prefix, key_hash, scopes, created_by, expires_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING " + apiKeyColumns
+	record, err := scanAPIKey(a.db.QueryRowContext(ctx, query, apiKey.Name, prefix, keyHash, pq.Array(scopes), createdBy, expiresAt))
+	if err != nil {
+		logQueryError(ctx, "Error while inserting data", err)
+		return nil, translateError(err, "api key")
+	}
+	return record, nil
+}
+
+func (a APIKeyStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKeyRecord, error) {
+	record, err := scanAPIKey(a.db.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash=$1", keyHash))
+	if err != nil {
+		return nil, translateError(err, "api key")
+	}
+	return record, nil
+}
+
+// Every key, revoked and expired ones included, oldest first
+func (a APIKeyStore) GetAllAPIKey(ctx context.Context) ([]models.APIKeyRecord, error) {
+	rows, err := a.db.QueryContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys ORDER BY created_at, id")
+	if err != nil {
+		logQueryError(ctx, "Error while retrieving data", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	apiKeys := make([]models.APIKeyRecord, 0)
+	for rows.Next() {
+		record, err := scanAPIKey(rows)
+		if err != nil {
+			logQueryError(ctx, "Error while retrieving data", err)
+			return nil, err
+		}
+		apiKeys = append(apiKeys, *record)
+	}
+	return apiKeys, rows.Err()
+}
+
+// RevokeAPIKey returns ErrNotFound when the key is unknown or already revoked
+func (a APIKeyStore) RevokeAPIKey(ctx context.Context, id string) (int64, error) {
+	result, err := a.db.ExecContext(ctx, "UPDATE api_keys SET revoked_at=CURRENT_TIMESTAMP WHERE id=$1 AND revoked_at IS NULL", id)
+	if err != nil {
+		logQueryError(ctx, "Error while updating data", err)
+		return -1, translateError(err, "api key")
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return -1, err
+	}
+	if rowAffected == 0 {
+		return 0, notFound("api key")
+	}
+	return rowAffected, nil
+}
+
+func (a APIKeyStore) UpdateAPIKeyLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := a.db.ExecContext(ctx, "UPDATE api_keys SET last_used_at=$1 WHERE id=$2", usedAt.UTC(), id)
+	if err != nil {
+		logQueryError(ctx, "Error while updating data", err)
+	}
+	return err
+}
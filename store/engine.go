@@ -3,147 +3,154 @@ package store
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
-	"log"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/google/uuid"
 	"github.com/harshitrajsinha/goserver-vanmango/models"
 )
 
-type engineQueryResponse struct {
-	ID            uuid.UUID `json:"id"`
-	Displacement  int64     `json:"displacement_in_cc"`
-	NoOfCylinders int       `json:"no_of_cylinders"`
-	Material      string    `json:"material"`
-	CreatedAt     string    `json:"-"`
-	UpdatedAt     string    `json:"-"`
+// Column list matching the field order scanned by scanEngine
+const engineColumns = "id, displacement_in_cc, no_of_cylinders, material, created_at, updated_at"
+
+// Columns engine lists can be sorted by, keyed by the sort query value
+var engineSortColumns = map[string]sortColumn{
+	"created_at":   {name: "created_at", cast: "timestamp"},
+	"displacement": {name: "displacement_in_cc", cast: "bigint"},
 }
 
 type EngineStore struct {
-	db *sql.DB
+	db tracedDB
 }
 
 // Constructor method for db variable
 func NewEngineStore(db *sql.DB) *EngineStore {
-	return &EngineStore{db: db}
+	return &EngineStore{db: tracedDB{DB: db}}
+}
+
+func scanEngine(row rowScanner, engine *models.EngineRecord) error {
+	return row.Scan(&engine.ID, &engine.Displacement, &engine.NoOfCylinders, &engine.Material, &engine.CreatedAt, &engine.UpdatedAt)
 }
 
-func (e EngineStore) GetEngineById(ctx context.Context, id string) (interface{}, error) {
-	var queryData engineQueryResponse
+func (e EngineStore) GetEngineById(ctx context.Context, id string) (*models.EngineRecord, error) {
+	var queryData models.EngineRecord
 
 	// Begin DB transaction
 	tx, err := e.db.BeginTx(ctx, nil)
 	if err != nil {
-		return engineQueryResponse{}, err
+		return nil, translateError(err, "engine")
 	}
 
-	defer func() {
-		if err != nil {
-			if rbErr := tx.Rollback(); rbErr != nil {
-				log.Println("Transaction rollback error: ", rbErr)
-			}
-		} else {
-			if cmErr := tx.Commit(); cmErr != nil {
-				log.Println("Commit rollback error: ", cmErr)
-			}
-		}
-	}()
-	err = tx.QueryRowContext(ctx, "SELECT * FROM engine WHERE id=$1", id).Scan(
-		&queryData.ID, &queryData.Displacement, &queryData.NoOfCylinders, &queryData.Material, &queryData.CreatedAt, &queryData.UpdatedAt)
+	defer func() { endTx(ctx, tx, err) }()
+	err = scanEngine(tx.QueryRowContext(ctx, "SELECT "+engineColumns+" FROM engine WHERE id=$1", id), &queryData)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return queryData, nil // return empty model
-		}
-		return queryData, err // return empty model
+		return nil, translateError(err, "engine") // ErrNotFound when no row matches
 	}
-	return queryData, err
+	return &queryData, nil
 }
 
-func (e EngineStore) GetAllEngine(ctx context.Context) (interface{}, error) {
+func (e EngineStore) GetAllEngine(ctx context.Context, filter *models.EngineFilter) ([]models.EngineRecord, *models.PageInfo, error) {
 
 	// Begin DB transaction
 	tx, err := e.db.BeginTx(ctx, nil)
 	if err != nil {
-		return engineQueryResponse{}, err
+		return nil, nil, translateError(err, "engine")
 	}
 
-	defer func() {
-		if err != nil {
-			if rbErr := tx.Rollback(); rbErr != nil {
-				log.Println("Transaction rollback error: ", rbErr)
-			}
-		} else {
-			if cmErr := tx.Commit(); cmErr != nil {
-				log.Println("Commit rollback error: ", cmErr)
-			}
-		}
-	}()
-	rows, err := tx.QueryContext(ctx, "SELECT * FROM engine;")
+	defer func() { endTx(ctx, tx, err) }()
+
+	conditions, args := engineFilterConditions(filter)
+	listQuery, listArgs, countQuery, countArgs := buildListQueries("engine", engineColumns, "id", engineSortColumns[filter.Sort], conditions, args, filter.ListOptions)
+
+	pageInfo := &models.PageInfo{Limit: filter.Limit, Offset: filter.Offset}
+	err = tx.QueryRowContext(ctx, countQuery, countArgs...).Scan(&pageInfo.Total)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return engineQueryResponse{}, nil // return empty model
-		}
-		return engineQueryResponse{}, err // return empty model
+		return nil, nil, translateError(err, "engine")
+	}
+
+	rows, err := tx.QueryContext(ctx, listQuery, listArgs...)
+	if err != nil {
+		return nil, nil, translateError(err, "engine")
 	}
 	defer rows.Close()
 
 	// slice to store all rows
-	allEngineData := make([]interface{}, 0)
+	allEngineData := make([]models.EngineRecord, 0)
 
 	// Get each row data into a slice
 	for rows.Next() {
-		var queryData engineQueryResponse
+		var queryData models.EngineRecord
 		// Return single row
-		err = rows.Scan(
-			&queryData.ID, &queryData.Displacement, &queryData.NoOfCylinders, &queryData.Material, &queryData.CreatedAt, &queryData.UpdatedAt)
+		err = scanEngine(rows, &queryData)
 		if err != nil {
-			return engineQueryResponse{}, err
+			return nil, nil, translateError(err, "engine")
 		}
 		// store each row
 		allEngineData = append(allEngineData, queryData)
 	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, translateError(err, "engine")
+	}
+
+	// an extra row means there is a next page, hand out a cursor pointing at the last row returned
+	if len(allEngineData) > filter.Limit {
+		allEngineData = allEngineData[:filter.Limit]
+		last := allEngineData[len(allEngineData)-1]
+		pageInfo.NextCursor = models.Cursor{Sort: filter.Sort, Order: filter.Order, Value: engineSortValue(last, filter.Sort), ID: last.ID}.Encode()
+	}
 
-	return allEngineData, nil
+	return allEngineData, pageInfo, nil
 }
 
-func (e EngineStore) CreateEngine(ctx context.Context, engineReq *models.Engine) (int64, error) {
+// Build WHERE conditions for the engine list filters
+func engineFilterConditions(filter *models.EngineFilter) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
 
-	// Begin DB transaction
-	tx, err := e.db.BeginTx(ctx, nil)
-	if err != nil {
-		log.Println("Error while inserting data ", err)
-		return -1, err
+	if filter.Material != "" {
+		args = append(args, strings.ToLower(filter.Material))
+		conditions = append(conditions, fmt.Sprintf("material=$%d", len(args)))
+	}
+	if filter.NoOfCylinders > 0 {
+		args = append(args, filter.NoOfCylinders)
+		conditions = append(conditions, fmt.Sprintf("no_of_cylinders=$%d", len(args)))
 	}
 
-	defer func() {
-		if err != nil {
-			if rbErr := tx.Rollback(); rbErr != nil {
-				log.Println("Transaction rollback error: ", rbErr)
-			}
-		} else {
-			if cmErr := tx.Commit(); cmErr != nil {
-				log.Println("Commit rollback error: ", cmErr)
-			}
-		}
-	}()
+	return conditions, args
+}
 
-	var query string = "INSERT INTO engine (displacement_in_cc, no_of_cylinders, material) VALUES ($1, $2, $3)"
-	result, err := tx.ExecContext(ctx, query, engineReq.Displacement, engineReq.NoOfCylinders, engineReq.Material)
+// Value of the sort column for a row, as stored in a cursor
+func engineSortValue(engine models.EngineRecord, sort string) string {
+	switch sort {
+	case "displacement":
+		return strconv.FormatInt(engine.Displacement, 10)
+	default:
+		return engine.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+func (e EngineStore) CreateEngine(ctx context.Context, engineReq *models.Engine) (*models.EngineRecord, error) {
+	var createdEngine models.EngineRecord
 
+	// Begin DB transaction
+	tx, err := e.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Println("Error while inserting data ", err)
-		return -1, err
+		logQueryError(ctx, "Error while inserting data", err)
+		return nil, translateError(err, "engine")
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	defer func() { endTx(ctx, tx, err) }()
+
+	var query string = "INSERT INTO engine (displacement_in_cc, no_of_cylinders, material) VALUES ($1, $2, $3) RETURNING " + engineColumns
+	err = scanEngine(tx.QueryRowContext(ctx, query, engineReq.Displacement, engineReq.NoOfCylinders, engineReq.Material), &createdEngine)
+
 	if err != nil {
-		log.Println("Error while inserting data ", err)
-		return -1, err
+		logQueryError(ctx, "Error while inserting data", err)
+		return nil, translateError(err, "engine")
 	}
 
-	return rowsAffected, nil
+	return &createdEngine, nil
 }
 
 func (e EngineStore) UpdateEngine(ctx context.Context, engineID string, engineReq *models.Engine) (int64, error) {
@@ -151,21 +158,11 @@ func (e EngineStore) UpdateEngine(ctx context.Context, engineID string, engineRe
 	// DB transaction
 	tx, err := e.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Println("Error while updating data ", err)
-		return -1, err
+		logQueryError(ctx, "Error while updating data", err)
+		return -1, translateError(err, "engine")
 	}
 
-	defer func() {
-		if err != nil {
-			if rbErr := tx.Rollback(); rbErr != nil {
-				fmt.Printf("Transaction rollback error: %v\n", rbErr)
-			}
-		} else {
-			if cmErr := tx.Commit(); cmErr != nil {
-				fmt.Printf("Transaction commit error: %v\n", cmErr)
-			}
-		}
-	}()
+	defer func() { endTx(ctx, tx, err) }()
 
 	var query strings.Builder
 	var args []interface{}
@@ -199,11 +196,18 @@ func (e EngineStore) UpdateEngine(ctx context.Context, engineID string, engineRe
 
 	result, err := tx.ExecContext(ctx, query.String(), args...)
 	if err != nil {
-		log.Println("Error while updating data ", err)
-		return -1, err
+		logQueryError(ctx, "Error while updating data", err)
+		return -1, translateError(err, "engine")
 	}
 
 	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return -1, err
+	}
+	if rowAffected == 0 {
+		return 0, notFound("engine")
+	}
+
 	return rowAffected, nil
 }
 
@@ -212,28 +216,23 @@ func (e EngineStore) DeleteEngine(ctx context.Context, id string) (int64, error)
 	// DB transaction
 	tx, err := e.db.BeginTx(ctx, nil)
 	if err != nil {
-		return -1, err
+		return -1, translateError(err, "engine")
 	}
 
-	defer func() {
-		if err != nil {
-			if rbErr := tx.Rollback(); rbErr != nil {
-				log.Println("Transaction rollback error: ", rbErr)
-			}
-		} else {
-			if cmErr := tx.Commit(); cmErr != nil {
-				log.Println("Commit rollback error: ", cmErr)
-			}
-		}
-	}()
+	defer func() { endTx(ctx, tx, err) }()
 
 	var query string = "DELETE FROM engine WHERE id=$1"
 	result, err := tx.ExecContext(ctx, query, id)
 	if err != nil {
-		return -1, err
+		return -1, translateError(err, "engine")
 	}
 	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return -1, err
+	}
+	if rowAffected == 0 {
+		return 0, notFound("engine")
+	}
 
 	return rowAffected, nil
-
 }
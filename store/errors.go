@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
+	"github.com/lib/pq"
+)
+
+// Sentinel errors returned by every store. Match them with errors.Is.
+var (
+	ErrNotFound   = errors.New("record not found")
+	ErrConflict   = errors.New("record already exists")
+	ErrForeignKey = errors.New("referenced record does not exist")
+	ErrValidation = errors.New("invalid value")
+)
+
+// Error pairs a sentinel kind with a message that is safe to show to API clients
+type Error struct {
+	Kind    error
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func notFound(entity string) error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf("%s not found", entity), Err: sql.ErrNoRows}
+}
+
+// Postgres error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pqUniqueViolation        = "23505"
+	pqForeignKeyViolation    = "23503"
+	pqNotNullViolation       = "23502"
+	pqCheckViolation         = "23514"
+	pqInvalidTextRepr        = "22P02"
+	pqStringDataTruncated    = "22001"
+	pqNumericValueOutOfRange = "22003"
+)
+
+// translateError maps driver errors onto the store sentinels, anything else is returned unchanged
+func translateError(err error, entity string) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFound(entity)
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case pqUniqueViolation:
+		return &Error{Kind: ErrConflict, Message: fmt.Sprintf("%s already exists", entity), Err: err}
+	case pqForeignKeyViolation:
+		return &Error{Kind: ErrForeignKey, Message: fmt.Sprintf("%s references a record that does not exist", entity), Err: err}
+	case pqNotNullViolation, pqCheckViolation, pqInvalidTextRepr, pqStringDataTruncated, pqNumericValueOutOfRange:
+		return &Error{Kind: ErrValidation, Message: fmt.Sprintf("invalid %s data: %s", entity, pqErr.Message), Err: err}
+	}
+	return err
+}
+
+// Log a failed statement with the request logger. Errors that map onto a sentinel
+// are caused by the request, not the server, and are logged below error level.
+func logQueryError(ctx context.Context, message string, err error) {
+	level := slog.LevelError
+	var storeErr *Error
+	if errors.As(translateError(err, ""), &storeErr) {
+		level = slog.LevelInfo
+	}
+	logging.FromContext(ctx).Log(ctx, level, message, "error", err)
+}
@@ -2,22 +2,65 @@ package store
 
 import (
 	"context"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/harshitrajsinha/goserver-vanmango/models"
 )
 
 type EngineStoreInterface interface {
-	GetEngineById(ctx context.Context, id string) (interface{}, error)
-	GetAllEngine(ctx context.Context) (interface{}, error)
-	CreateEngine(ctx context.Context, engineReq *models.Engine) (int64, error)
+	GetEngineById(ctx context.Context, id string) (*models.EngineRecord, error)
+	GetAllEngine(ctx context.Context, filter *models.EngineFilter) ([]models.EngineRecord, *models.PageInfo, error)
+	CreateEngine(ctx context.Context, engineReq *models.Engine) (*models.EngineRecord, error)
 	UpdateEngine(ctx context.Context, id string, engineReq *models.Engine) (int64, error)
 	DeleteEngine(ctx context.Context, id string) (int64, error)
 }
 
 type VanStoreInterface interface {
-	GetVanById(ctx context.Context, id string) (interface{}, error)
-	GetAllVan(ctx context.Context) (interface{}, error)
-	CreateVan(ctx context.Context, vanReq *models.Van) (int64, error)
+	GetVanById(ctx context.Context, id string) (*models.VanRecord, error)
+	GetAllVan(ctx context.Context, filter *models.VanFilter) ([]models.VanRecord, *models.PageInfo, error)
+	SearchVan(ctx context.Context, search *models.VanSearch) ([]models.VanSearchResult, *models.PageInfo, error)
+	CreateVan(ctx context.Context, vanReq *models.Van) (*models.VanRecord, error)
 	UpdateVan(ctx context.Context, id string, vanReq *models.Van) (int64, error)
 	DeleteVan(ctx context.Context, id string) (int64, error)
 }
+
+type UserStoreInterface interface {
+	GetUserByID(ctx context.Context, id string) (*models.UserRecord, error)
+	GetUserByUsername(ctx context.Context, username string) (*models.UserRecord, error)
+	CreateUser(ctx context.Context, username string, passwordHash string, role models.Role) (*models.UserRecord, error)
+	UpdatePassword(ctx context.Context, id string, passwordHash string) (int64, error)
+	UpdateRole(ctx context.Context, id string, role models.Role) (int64, error)
+}
+
+type TokenStoreInterface interface {
+	CreateRefreshToken(ctx context.Context, userID uuid.UUID, familyID uuid.UUID, tokenHash string, expiresAt time.Time) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshTokenRecord, error)
+	UseRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshTokenRecord, error)
+	RevokeRefreshFamily(ctx context.Context, familyID uuid.UUID) (int64, error)
+	RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) (int64, error)
+	RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+type APIKeyStoreInterface interface {
+	CreateAPIKey(ctx context.Context, apiKey *models.APIKey, prefix string, keyHash string, createdBy string) (*models.APIKeyRecord, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKeyRecord, error)
+	GetAllAPIKey(ctx context.Context) ([]models.APIKeyRecord, error)
+	RevokeAPIKey(ctx context.Context, id string) (int64, error)
+	UpdateAPIKeyLastUsed(ctx context.Context, id string, usedAt time.Time) error
+}
+
+// Failed login counters, keyed by username or client address. Kept in process
+// memory by default, a shared implementation lets every instance see the same lockouts.
+type LoginAttemptStoreInterface interface {
+	GetLoginAttempts(ctx context.Context, key string) (*models.LoginAttempts, error)
+	// RecordLoginFailure counts a failure, starting over when the last one is older than window
+	RecordLoginFailure(ctx context.Context, key string, window time.Duration) (*models.LoginAttempts, error)
+	LockLogin(ctx context.Context, key string, until time.Time) error
+	ClearLoginAttempts(ctx context.Context, key string) error
+}
+
+type LoginEventStoreInterface interface {
+	CreateLoginEvent(ctx context.Context, event *models.LoginEvent) error
+}
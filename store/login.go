@@ -0,0 +1,27 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+)
+
+type LoginEventStore struct {
+	db tracedDB
+}
+
+// Constructor method for db variable
+func NewLoginEventStore(db *sql.DB) *LoginEventStore {
+	return &LoginEventStore{db: tracedDB{DB: db}}
+}
+
+func (l LoginEventStore) CreateLoginEvent(ctx context.Context, event *models.LoginEvent) error {
+	var query string = "INSERT INTO login_events (username, user_id, ip, outcome) VALUES ($1, $2, $3, $4)"
+	_, err := l.db.ExecContext(ctx, query, event.Username, event.UserID, event.IP, event.Outcome)
+	if err != nil {
+		logQueryError(ctx, "Error while inserting data", err)
+		return translateError(err, "login event")
+	}
+	return nil
+}
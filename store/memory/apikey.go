@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+)
+
+type APIKeyStore struct {
+	db *DB
+}
+
+func NewAPIKeyStore(db *DB) *APIKeyStore {
+	return &APIKeyStore{db: db}
+}
+
+func (a *APIKeyStore) CreateAPIKey(ctx context.Context, request *models.APIKey, prefix string, keyHash string, createdBy string) (*models.APIKeyRecord, error) {
+	userID, err := parseID(createdBy, "api key")
+	if err != nil {
+		return nil, err
+	}
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	if _, ok := a.db.users[userID]; !ok {
+		return nil, foreignKey("api key")
+	}
+	for _, existing := range a.db.apiKeys {
+		if existing.KeyHash == keyHash {
+			return nil, conflict("api key")
+		}
+	}
+
+	record := models.APIKeyRecord{
+		ID:        uuid.New(),
+		Name:      request.Name,
+		Prefix:    prefix,
+		Scopes:    append([]models.Permission{}, request.Scopes...),
+		CreatedBy: &userID,
+		CreatedAt: now(),
+	}
+	if request.ExpiresAt != nil {
+		expiresAt := request.ExpiresAt.UTC().Truncate(time.Microsecond)
+		record.ExpiresAt = &expiresAt
+	}
+	a.db.apiKeys[record.ID] = apiKey{APIKeyRecord: record, KeyHash: keyHash}
+	return &record, nil
+}
+
+func (a *APIKeyStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKeyRecord, error) {
+	a.db.mu.RLock()
+	defer a.db.mu.RUnlock()
+
+	for _, existing := range a.db.apiKeys {
+		if existing.KeyHash == keyHash {
+			return &existing.APIKeyRecord, nil
+		}
+	}
+	return nil, notFound("api key")
+}
+
+// Every key, revoked and expired ones included, oldest first
+func (a *APIKeyStore) GetAllAPIKey(ctx context.Context) ([]models.APIKeyRecord, error) {
+	a.db.mu.RLock()
+	defer a.db.mu.RUnlock()
+
+	apiKeys := make([]models.APIKeyRecord, 0, len(a.db.apiKeys))
+	for _, existing := range a.db.apiKeys {
+		apiKeys = append(apiKeys, existing.APIKeyRecord)
+	}
+	sort.Slice(apiKeys, func(i, j int) bool {
+		key := func(record models.APIKeyRecord) sortKey {
+			return sortKey{number: record.CreatedAt.UnixNano(), id: record.ID}
+		}
+		return key(apiKeys[i]).compare(key(apiKeys[j])) < 0
+	})
+	return apiKeys, nil
+}
+
+// RevokeAPIKey returns ErrNotFound when the key is unknown or already revoked
+func (a *APIKeyStore) RevokeAPIKey(ctx context.Context, id string) (int64, error) {
+	keyID, err := parseID(id, "api key")
+	if err != nil {
+		return -1, err
+	}
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	existing, ok := a.db.apiKeys[keyID]
+	if !ok || existing.RevokedAt != nil {
+		return 0, notFound("api key")
+	}
+	revokedAt := now()
+	existing.RevokedAt = &revokedAt
+	a.db.apiKeys[keyID] = existing
+	return 1, nil
+}
+
+func (a *APIKeyStore) UpdateAPIKeyLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	keyID, err := parseID(id, "api key")
+	if err != nil {
+		return err
+	}
+
+	a.db.mu.Lock()
+	defer a.db.mu.Unlock()
+
+	if existing, ok := a.db.apiKeys[keyID]; ok {
+		lastUsedAt := usedAt.UTC().Truncate(time.Microsecond)
+		existing.LastUsedAt = &lastUsedAt
+		a.db.apiKeys[keyID] = existing
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+)
+
+type EngineStore struct {
+	db *DB
+}
+
+func NewEngineStore(db *DB) *EngineStore {
+	return &EngineStore{db: db}
+}
+
+func engineSortKey(sort string) func(models.EngineRecord) sortKey {
+	return func(engine models.EngineRecord) sortKey {
+		if sort == "displacement" {
+			return sortKey{number: engine.Displacement, id: engine.ID}
+		}
+		return sortKey{number: engine.CreatedAt.UnixNano(), id: engine.ID}
+	}
+}
+
+// Value of the sort field for a row, as stored in a cursor
+func engineSortValue(engine models.EngineRecord, sort string) string {
+	if sort == "displacement" {
+		return strconv.FormatInt(engine.Displacement, 10)
+	}
+	return engine.CreatedAt.Format(time.RFC3339Nano)
+}
+
+func (e *EngineStore) GetEngineById(ctx context.Context, id string) (*models.EngineRecord, error) {
+	engineID, err := parseID(id, "engine")
+	if err != nil {
+		return nil, err
+	}
+
+	e.db.mu.RLock()
+	defer e.db.mu.RUnlock()
+
+	engine, ok := e.db.engines[engineID]
+	if !ok {
+		return nil, notFound("engine")
+	}
+	return &engine, nil
+}
+
+func (e *EngineStore) GetAllEngine(ctx context.Context, filter *models.EngineFilter) ([]models.EngineRecord, *models.PageInfo, error) {
+	e.db.mu.RLock()
+	defer e.db.mu.RUnlock()
+
+	allEngineData := make([]models.EngineRecord, 0)
+	for _, engine := range e.db.engines {
+		if filter.Material != "" && engine.Material != strings.ToLower(filter.Material) {
+			continue
+		}
+		if filter.NoOfCylinders > 0 && engine.NoOfCylinders != filter.NoOfCylinders {
+			continue
+		}
+		allEngineData = append(allEngineData, engine)
+	}
+
+	pageInfo := &models.PageInfo{Total: int64(len(allEngineData)), Limit: filter.Limit, Offset: filter.Offset}
+	allEngineData, err := paginate(allEngineData, engineSortKey(filter.Sort), filter.ListOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// an extra row means there is a next page, hand out a cursor pointing at the last row returned
+	if len(allEngineData) > filter.Limit {
+		allEngineData = allEngineData[:filter.Limit]
+		last := allEngineData[len(allEngineData)-1]
+		pageInfo.NextCursor = models.Cursor{Sort: filter.Sort, Order: filter.Order, Value: engineSortValue(last, filter.Sort), ID: last.ID}.Encode()
+	}
+
+	return allEngineData, pageInfo, nil
+}
+
+func checkEngine(engineReq *models.Engine) error {
+	return checkEnum("engine", "engine_material", engineReq.Material, "aluminium", "iron")
+}
+
+func (e *EngineStore) CreateEngine(ctx context.Context, engineReq *models.Engine) (*models.EngineRecord, error) {
+	if err := checkEngine(engineReq); err != nil {
+		return nil, err
+	}
+
+	e.db.mu.Lock()
+	defer e.db.mu.Unlock()
+
+	createdAt := now()
+	engine := models.EngineRecord{
+		ID:            uuid.New(),
+		Displacement:  engineReq.Displacement,
+		NoOfCylinders: engineReq.NoOfCylinders,
+		Material:      engineReq.Material,
+		CreatedAt:     createdAt,
+		UpdatedAt:     createdAt,
+	}
+	e.db.engines[engine.ID] = engine
+	return &engine, nil
+}
+
+// Only non-zero fields are written, like the partial UPDATE built by the Postgres store
+func (e *EngineStore) UpdateEngine(ctx context.Context, id string, engineReq *models.Engine) (int64, error) {
+	engineID, err := parseID(id, "engine")
+	if err != nil {
+		return -1, err
+	}
+	if err := checkEngine(engineReq); err != nil {
+		return -1, err
+	}
+
+	e.db.mu.Lock()
+	defer e.db.mu.Unlock()
+
+	engine, ok := e.db.engines[engineID]
+	if !ok {
+		return 0, notFound("engine")
+	}
+
+	if engineReq.Displacement > 0 {
+		engine.Displacement = engineReq.Displacement
+	}
+	if engineReq.NoOfCylinders > 0 {
+		engine.NoOfCylinders = engineReq.NoOfCylinders
+	}
+	if engineReq.Material != "" {
+		engine.Material = engineReq.Material
+	}
+	engine.UpdatedAt = now()
+
+	e.db.engines[engineID] = engine
+	return 1, nil
+}
+
+// Deleting an engine removes the vans built on it, matching ON DELETE CASCADE
+func (e *EngineStore) DeleteEngine(ctx context.Context, id string) (int64, error) {
+	engineID, err := parseID(id, "engine")
+	if err != nil {
+		return -1, err
+	}
+
+	e.db.mu.Lock()
+	defer e.db.mu.Unlock()
+
+	if _, ok := e.db.engines[engineID]; !ok {
+		return 0, notFound("engine")
+	}
+
+	delete(e.db.engines, engineID)
+	for vanID, van := range e.db.vans {
+		if van.EngineID == engineID {
+			delete(e.db.vans, vanID)
+		}
+	}
+	return 1, nil
+}
@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+)
+
+// Oldest login events are dropped beyond this many, the memory store is not an archive
+const maxLoginEvents = 10000
+
+type LoginEventStore struct {
+	db *DB
+}
+
+func NewLoginEventStore(db *DB) *LoginEventStore {
+	return &LoginEventStore{db: db}
+}
+
+func (l *LoginEventStore) CreateLoginEvent(ctx context.Context, event *models.LoginEvent) error {
+	l.db.mu.Lock()
+	defer l.db.mu.Unlock()
+
+	record := *event
+	record.ID = uuid.New()
+	record.CreatedAt = now()
+	l.db.loginEvents = append(l.db.loginEvents, record)
+	if len(l.db.loginEvents) > maxLoginEvents {
+		l.db.loginEvents = l.db.loginEvents[len(l.db.loginEvents)-maxLoginEvents:]
+	}
+	return nil
+}
+
+// LoginAttemptStore keeps failed login counters for this process only. Unlike the
+// other memory stores it is also used with Postgres, so it has its own lock and map.
+type LoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]models.LoginAttempts
+}
+
+func NewLoginAttemptStore() *LoginAttemptStore {
+	return &LoginAttemptStore{attempts: make(map[string]models.LoginAttempts)}
+}
+
+func (l *LoginAttemptStore) GetLoginAttempts(ctx context.Context, key string) (*models.LoginAttempts, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	attempts := l.attempts[key]
+	return &attempts, nil
+}
+
+func (l *LoginAttemptStore) RecordLoginFailure(ctx context.Context, key string, window time.Duration) (*models.LoginAttempts, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current := time.Now()
+	attempts := l.attempts[key]
+	if current.Sub(attempts.LastFailure) > window {
+		attempts.Failures = 0
+	}
+	attempts.Failures++
+	attempts.LastFailure = current
+	l.attempts[key] = attempts
+
+	// counters nobody has failed against for a whole window are forgotten, keep the map small
+	for existingKey, existing := range l.attempts {
+		if current.Sub(existing.LastFailure) > window && current.After(existing.LockedUntil) {
+			delete(l.attempts, existingKey)
+		}
+	}
+	return &attempts, nil
+}
+
+func (l *LoginAttemptStore) LockLogin(ctx context.Context, key string, until time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	attempts := l.attempts[key]
+	attempts.LockedUntil = until
+	l.attempts[key] = attempts
+	return nil
+}
+
+func (l *LoginAttemptStore) ClearLoginAttempts(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.attempts, key)
+	return nil
+}
@@ -0,0 +1,185 @@
+// Package memory keeps every record in process memory. It implements the same
+// store interfaces as the Postgres stores, including the sentinel errors, so the
+// API can run in tests and local demos without a database.
+package memory
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+	"github.com/harshitrajsinha/goserver-vanmango/store"
+)
+
+// The memory stores are drop-in replacements for the Postgres ones
+var (
+	_ store.EngineStoreInterface = (*EngineStore)(nil)
+	_ store.VanStoreInterface    = (*VanStore)(nil)
+	_ store.UserStoreInterface   = (*UserStore)(nil)
+	_ store.TokenStoreInterface  = (*TokenStore)(nil)
+	_ store.APIKeyStoreInterface = (*APIKeyStore)(nil)
+
+	_ store.LoginEventStoreInterface   = (*LoginEventStore)(nil)
+	_ store.LoginAttemptStoreInterface = (*LoginAttemptStore)(nil)
+)
+
+// DB holds the tables shared by the memory stores. One lock guards all of them
+// so cross-table rules such as the engine to van cascade stay consistent.
+type DB struct {
+	mu            sync.RWMutex
+	engines       map[uuid.UUID]models.EngineRecord
+	vans          map[uuid.UUID]models.VanRecord
+	users         map[uuid.UUID]models.UserRecord
+	refreshTokens map[string]refreshToken
+	revokedTokens map[string]time.Time
+	apiKeys       map[uuid.UUID]apiKey
+	loginEvents   []models.LoginEvent
+}
+
+// A stored refresh token, keyed by its hash
+type refreshToken struct {
+	models.RefreshTokenRecord
+	TokenHash string
+}
+
+// A stored API key with the hash it is looked up by
+type apiKey struct {
+	models.APIKeyRecord
+	KeyHash string
+}
+
+func NewDB() *DB {
+	return &DB{
+		engines:       make(map[uuid.UUID]models.EngineRecord),
+		vans:          make(map[uuid.UUID]models.VanRecord),
+		users:         make(map[uuid.UUID]models.UserRecord),
+		refreshTokens: make(map[string]refreshToken),
+		revokedTokens: make(map[string]time.Time),
+		apiKeys:       make(map[uuid.UUID]apiKey),
+	}
+}
+
+// Timestamps are kept at microsecond precision like Postgres TIMESTAMP columns
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func notFound(entity string) error {
+	return &store.Error{Kind: store.ErrNotFound, Message: fmt.Sprintf("%s not found", entity)}
+}
+
+func conflict(entity string) error {
+	return &store.Error{Kind: store.ErrConflict, Message: fmt.Sprintf("%s already exists", entity)}
+}
+
+func foreignKey(entity string) error {
+	return &store.Error{Kind: store.ErrForeignKey, Message: fmt.Sprintf("%s references a record that does not exist", entity)}
+}
+
+// Postgres rejects malformed UUIDs before looking anything up
+func parseID(id string, entity string) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, &store.Error{Kind: store.ErrValidation, Message: fmt.Sprintf("invalid %s data: invalid input syntax for type uuid", entity)}
+	}
+	return parsed, nil
+}
+
+// Postgres enum columns only accept their exact lowercase labels, empty values are left unset
+func checkEnum(entity string, enum string, value string, labels ...string) error {
+	if value == "" {
+		return nil
+	}
+	for _, label := range labels {
+		if value == label {
+			return nil
+		}
+	}
+	return &store.Error{Kind: store.ErrValidation, Message: fmt.Sprintf("invalid %s data: invalid input value for enum %s: \"%s\"", entity, enum, value)}
+}
+
+// Position of a row in a sorted list, compared numerically, then as text, then by id
+type sortKey struct {
+	number int64
+	text   string
+	id     uuid.UUID
+}
+
+func (k sortKey) compare(other sortKey) int {
+	switch {
+	case k.number < other.number:
+		return -1
+	case k.number > other.number:
+		return 1
+	case k.text < other.text:
+		return -1
+	case k.text > other.text:
+		return 1
+	}
+	return bytes.Compare(k.id[:], other.id[:])
+}
+
+// Rebuild the sort key of the row a cursor points at
+func cursorKey(cursor *models.Cursor) (sortKey, error) {
+	key := sortKey{id: cursor.ID}
+	switch cursor.Sort {
+	case "created_at":
+		createdAt, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return key, &store.Error{Kind: store.ErrValidation, Message: "cursor is invalid"}
+		}
+		key.number = createdAt.UnixNano()
+	case "name":
+		key.text = cursor.Value
+	default:
+		number, err := strconv.ParseInt(cursor.Value, 10, 64)
+		if err != nil {
+			return key, &store.Error{Kind: store.ErrValidation, Message: "cursor is invalid"}
+		}
+		key.number = number
+	}
+	return key, nil
+}
+
+// Sort the filtered rows and cut out the requested page, mirroring buildListQueries:
+// keyset condition first, then offset, then one row past the limit.
+func paginate[T any](rows []T, key func(T) sortKey, opts models.ListOptions) ([]T, error) {
+
+	desc := opts.Order == "desc"
+	sort.Slice(rows, func(i, j int) bool {
+		if desc {
+			return key(rows[i]).compare(key(rows[j])) > 0
+		}
+		return key(rows[i]).compare(key(rows[j])) < 0
+	})
+
+	if opts.After != nil {
+		after, err := cursorKey(opts.After)
+		if err != nil {
+			return nil, err
+		}
+		start := len(rows)
+		for i, row := range rows {
+			cmp := key(row).compare(after)
+			if (!desc && cmp > 0) || (desc && cmp < 0) {
+				start = i
+				break
+			}
+		}
+		rows = rows[start:]
+	}
+
+	if opts.Offset >= len(rows) {
+		return []T{}, nil
+	}
+	rows = rows[opts.Offset:]
+	if len(rows) > opts.Limit+1 {
+		rows = rows[:opts.Limit+1]
+	}
+	return rows, nil
+}
@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+)
+
+// Weights of a hit in name/brand and in description, the ts_rank defaults for A and B
+const (
+	titleWeight       = 1.0
+	descriptionWeight = 0.4
+)
+
+var wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// A word, or a quoted phrase, from the search text
+type searchTerm struct {
+	words  []string
+	negate bool
+}
+
+// Search text in websearch_to_tsquery syntax: alternatives separated by "or",
+// each one a list of terms that must all match
+type searchQuery struct {
+	alternatives [][]searchTerm
+}
+
+func parseSearchQuery(text string) searchQuery {
+	var query searchQuery
+	var current []searchTerm
+
+	for len(text) > 0 {
+		text = strings.TrimLeft(text, " \t\n")
+		if text == "" {
+			break
+		}
+
+		negate := false
+		if text[0] == '-' {
+			negate = true
+			text = text[1:]
+		}
+
+		var token string
+		if strings.HasPrefix(text, `"`) {
+			end := strings.Index(text[1:], `"`)
+			if end < 0 {
+				token, text = text[1:], ""
+			} else {
+				token, text = text[1:end+1], text[end+2:]
+			}
+		} else {
+			end := strings.IndexAny(text, " \t\n")
+			if end < 0 {
+				token, text = text, ""
+			} else {
+				token, text = text[:end], text[end:]
+			}
+			if !negate && strings.EqualFold(token, "or") {
+				if len(current) > 0 {
+					query.alternatives = append(query.alternatives, current)
+				}
+				current = nil
+				continue
+			}
+		}
+
+		words := splitWords(token)
+		if len(words) > 0 {
+			current = append(current, searchTerm{words: words, negate: negate})
+		}
+	}
+	if len(current) > 0 {
+		query.alternatives = append(query.alternatives, current)
+	}
+	return query
+}
+
+func splitWords(text string) []string {
+	return wordPattern.FindAllString(strings.ToLower(text), -1)
+}
+
+// Count the places the term occurs, each word matching by prefix
+func (t searchTerm) hits(words []string) int {
+	count := 0
+	for i := 0; i+len(t.words) <= len(words); i++ {
+		matched := true
+		for j, word := range t.words {
+			if !strings.HasPrefix(words[i+j], word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			count++
+		}
+	}
+	return count
+}
+
+// Rank a van against the query, false when it does not match
+func (q searchQuery) rank(van models.VanRecord) (float64, bool) {
+	title := splitWords(van.Name + " " + van.Brand)
+	description := splitWords(van.Description)
+
+	best, found := 0.0, false
+	for _, terms := range q.alternatives {
+		rank, matched := 0.0, true
+		for _, term := range terms {
+			titleHits, descriptionHits := term.hits(title), term.hits(description)
+			if term.negate {
+				matched = titleHits+descriptionHits == 0
+			} else {
+				matched = titleHits+descriptionHits > 0
+				rank += float64(titleHits)*titleWeight + float64(descriptionHits)*descriptionWeight
+			}
+			if !matched {
+				break
+			}
+		}
+		if matched && (!found || rank > best) {
+			best, found = rank, true
+		}
+	}
+	return best, found
+}
+
+// Wrap every word that starts with a searched word in <mark> tags
+func (q searchQuery) highlight(text string) string {
+	var searched []string
+	for _, terms := range q.alternatives {
+		for _, term := range terms {
+			if !term.negate {
+				searched = append(searched, term.words...)
+			}
+		}
+	}
+
+	return wordPattern.ReplaceAllStringFunc(text, func(word string) string {
+		lower := strings.ToLower(word)
+		for _, prefix := range searched {
+			if strings.HasPrefix(lower, prefix) {
+				return "<mark>" + word + "</mark>"
+			}
+		}
+		return word
+	})
+}
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+)
+
+type TokenStore struct {
+	db *DB
+}
+
+func NewTokenStore(db *DB) *TokenStore {
+	return &TokenStore{db: db}
+}
+
+func (t *TokenStore) CreateRefreshToken(ctx context.Context, userID uuid.UUID, familyID uuid.UUID, tokenHash string, expiresAt time.Time) error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	if _, ok := t.db.users[userID]; !ok {
+		return foreignKey("refresh token")
+	}
+	if _, ok := t.db.refreshTokens[tokenHash]; ok {
+		return conflict("refresh token")
+	}
+
+	t.db.refreshTokens[tokenHash] = refreshToken{
+		RefreshTokenRecord: models.RefreshTokenRecord{
+			ID:        uuid.New(),
+			UserID:    userID,
+			FamilyID:  familyID,
+			ExpiresAt: expiresAt.UTC(),
+			CreatedAt: now(),
+		},
+		TokenHash: tokenHash,
+	}
+	return nil
+}
+
+func (t *TokenStore) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshTokenRecord, error) {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	token, ok := t.db.refreshTokens[tokenHash]
+	if !ok {
+		return nil, notFound("refresh token")
+	}
+	return &token.RefreshTokenRecord, nil
+}
+
+// UseRefreshToken marks a live token as used so it can be exchanged only once.
+// Returns ErrNotFound when the token is unknown, expired, revoked or already used.
+func (t *TokenStore) UseRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshTokenRecord, error) {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	token, ok := t.db.refreshTokens[tokenHash]
+	if !ok || token.UsedAt != nil || token.RevokedAt != nil || !token.ExpiresAt.After(time.Now()) {
+		return nil, notFound("refresh token")
+	}
+
+	usedAt := now()
+	token.UsedAt = &usedAt
+	t.db.refreshTokens[tokenHash] = token
+	return &token.RefreshTokenRecord, nil
+}
+
+func (t *TokenStore) RevokeRefreshFamily(ctx context.Context, familyID uuid.UUID) (int64, error) {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	var revoked int64
+	revokedAt := now()
+	for tokenHash, token := range t.db.refreshTokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &revokedAt
+			t.db.refreshTokens[tokenHash] = token
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func (t *TokenStore) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) (int64, error) {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	var revoked int64
+	revokedAt := now()
+	for tokenHash, token := range t.db.refreshTokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &revokedAt
+			t.db.refreshTokens[tokenHash] = token
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func (t *TokenStore) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	if _, ok := t.db.revokedTokens[jti]; !ok {
+		t.db.revokedTokens[jti] = expiresAt.UTC()
+	}
+
+	// expired tokens are rejected anyway, keep the denylist small
+	current := time.Now()
+	for revokedJTI, revokedExpiry := range t.db.revokedTokens {
+		if revokedExpiry.Before(current) {
+			delete(t.db.revokedTokens, revokedJTI)
+		}
+	}
+	return nil
+}
+
+func (t *TokenStore) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	t.db.mu.RLock()
+	defer t.db.mu.RUnlock()
+
+	_, revoked := t.db.revokedTokens[jti]
+	return revoked, nil
+}
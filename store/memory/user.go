@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+)
+
+type UserStore struct {
+	db *DB
+}
+
+func NewUserStore(db *DB) *UserStore {
+	return &UserStore{db: db}
+}
+
+func checkRole(role models.Role) error {
+	return checkEnum("user", "user_role", string(role), string(models.RoleAdmin), string(models.RoleFleetManager), string(models.RoleViewer))
+}
+
+func (u *UserStore) GetUserByID(ctx context.Context, id string) (*models.UserRecord, error) {
+	userID, err := parseID(id, "user")
+	if err != nil {
+		return nil, err
+	}
+
+	u.db.mu.RLock()
+	defer u.db.mu.RUnlock()
+
+	user, ok := u.db.users[userID]
+	if !ok {
+		return nil, notFound("user")
+	}
+	return &user, nil
+}
+
+// Username matching is case-insensitive
+func (u *UserStore) GetUserByUsername(ctx context.Context, username string) (*models.UserRecord, error) {
+	u.db.mu.RLock()
+	defer u.db.mu.RUnlock()
+
+	if user, ok := u.findByUsername(username); ok {
+		return &user, nil
+	}
+	return nil, notFound("user")
+}
+
+// Callers hold the lock
+func (u *UserStore) findByUsername(username string) (models.UserRecord, bool) {
+	for _, user := range u.db.users {
+		if strings.EqualFold(user.Username, username) {
+			return user, true
+		}
+	}
+	return models.UserRecord{}, false
+}
+
+func (u *UserStore) CreateUser(ctx context.Context, username string, passwordHash string, role models.Role) (*models.UserRecord, error) {
+	if err := checkRole(role); err != nil {
+		return nil, err
+	}
+
+	u.db.mu.Lock()
+	defer u.db.mu.Unlock()
+
+	// unique index on LOWER(username)
+	if _, ok := u.findByUsername(username); ok {
+		return nil, conflict("user")
+	}
+
+	createdAt := now()
+	user := models.UserRecord{
+		ID:           uuid.New(),
+		Username:     username,
+		PasswordHash: passwordHash,
+		Role:         role,
+		CreatedAt:    createdAt,
+		UpdatedAt:    createdAt,
+	}
+	u.db.users[user.ID] = user
+	return &user, nil
+}
+
+func (u *UserStore) UpdatePassword(ctx context.Context, id string, passwordHash string) (int64, error) {
+	return u.update(id, func(user *models.UserRecord) {
+		user.PasswordHash = passwordHash
+	})
+}
+
+func (u *UserStore) UpdateRole(ctx context.Context, id string, role models.Role) (int64, error) {
+	if err := checkRole(role); err != nil {
+		return -1, err
+	}
+	return u.update(id, func(user *models.UserRecord) {
+		user.Role = role
+	})
+}
+
+func (u *UserStore) update(id string, apply func(user *models.UserRecord)) (int64, error) {
+	userID, err := parseID(id, "user")
+	if err != nil {
+		return -1, err
+	}
+
+	u.db.mu.Lock()
+	defer u.db.mu.Unlock()
+
+	user, ok := u.db.users[userID]
+	if !ok {
+		return 0, notFound("user")
+	}
+	apply(&user)
+	user.UpdatedAt = now()
+	u.db.users[userID] = user
+	return 1, nil
+}
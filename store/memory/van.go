@@ -0,0 +1,255 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+)
+
+type VanStore struct {
+	db *DB
+}
+
+func NewVanStore(db *DB) *VanStore {
+	return &VanStore{db: db}
+}
+
+func vanSortKey(sort string) func(models.VanRecord) sortKey {
+	return func(van models.VanRecord) sortKey {
+		switch sort {
+		case "price":
+			return sortKey{number: van.Price, id: van.ID}
+		case "name":
+			return sortKey{text: van.Name, id: van.ID}
+		default:
+			return sortKey{number: van.CreatedAt.UnixNano(), id: van.ID}
+		}
+	}
+}
+
+// Value of the sort field for a row, as stored in a cursor
+func vanSortValue(van models.VanRecord, sort string) string {
+	switch sort {
+	case "price":
+		return strconv.FormatInt(van.Price, 10)
+	case "name":
+		return van.Name
+	default:
+		return van.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+func vanMatchesFilter(van models.VanRecord, filter *models.VanFilter) bool {
+	if filter.Category != "" && van.Category != strings.ToLower(filter.Category) {
+		return false
+	}
+	if filter.FuelType != "" && van.FuelType != strings.ToLower(filter.FuelType) {
+		return false
+	}
+	if filter.Brand != "" && !strings.EqualFold(van.Brand, filter.Brand) {
+		return false
+	}
+	if filter.EngineID != "" && van.EngineID.String() != strings.ToLower(filter.EngineID) {
+		return false
+	}
+	if filter.MinPrice > 0 && van.Price < filter.MinPrice {
+		return false
+	}
+	if filter.MaxPrice > 0 && van.Price > filter.MaxPrice {
+		return false
+	}
+	return true
+}
+
+func checkVan(vanReq *models.Van) error {
+	if err := checkEnum("van", "category", vanReq.Category, "simple", "rugged", "luxury"); err != nil {
+		return err
+	}
+	return checkEnum("van", "fuel_type", vanReq.FuelType, "petrol", "diesel", "gasoline")
+}
+
+func (v *VanStore) GetVanById(ctx context.Context, id string) (*models.VanRecord, error) {
+	vanID, err := parseID(id, "van")
+	if err != nil {
+		return nil, err
+	}
+
+	v.db.mu.RLock()
+	defer v.db.mu.RUnlock()
+
+	van, ok := v.db.vans[vanID]
+	if !ok {
+		return nil, notFound("van")
+	}
+	return &van, nil
+}
+
+func (v *VanStore) GetAllVan(ctx context.Context, filter *models.VanFilter) ([]models.VanRecord, *models.PageInfo, error) {
+	v.db.mu.RLock()
+	defer v.db.mu.RUnlock()
+
+	vanData := make([]models.VanRecord, 0)
+	for _, van := range v.db.vans {
+		if vanMatchesFilter(van, filter) {
+			vanData = append(vanData, van)
+		}
+	}
+
+	pageInfo := &models.PageInfo{Total: int64(len(vanData)), Limit: filter.Limit, Offset: filter.Offset}
+	vanData, err := paginate(vanData, vanSortKey(filter.Sort), filter.ListOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// an extra row means there is a next page, hand out a cursor pointing at the last row returned
+	if len(vanData) > filter.Limit {
+		vanData = vanData[:filter.Limit]
+		last := vanData[len(vanData)-1]
+		pageInfo.NextCursor = models.Cursor{Sort: filter.Sort, Order: filter.Order, Value: vanSortValue(last, filter.Sort), ID: last.ID}.Encode()
+	}
+
+	return vanData, pageInfo, nil
+}
+
+// SearchVan approximates the Postgres full-text search: words match by prefix instead
+// of by stem, name and brand hits outrank description hits, and the snippet is the
+// whole text with every match wrapped in <mark> tags.
+func (v *VanStore) SearchVan(ctx context.Context, search *models.VanSearch) ([]models.VanSearchResult, *models.PageInfo, error) {
+	query := parseSearchQuery(search.Query)
+
+	v.db.mu.RLock()
+	defer v.db.mu.RUnlock()
+
+	searchData := make([]models.VanSearchResult, 0)
+	for _, van := range v.db.vans {
+		rank, ok := query.rank(van)
+		if !ok {
+			continue
+		}
+		searchData = append(searchData, models.VanSearchResult{
+			VanRecord: van,
+			Rank:      rank,
+			Snippet:   query.highlight(van.Name + " - " + van.Brand + ": " + van.Description),
+		})
+	}
+
+	sort.Slice(searchData, func(i, j int) bool {
+		if searchData[i].Rank != searchData[j].Rank {
+			return searchData[i].Rank > searchData[j].Rank
+		}
+		return searchData[i].ID.String() < searchData[j].ID.String()
+	})
+
+	pageInfo := &models.PageInfo{Total: int64(len(searchData)), Limit: search.Limit, Offset: search.Offset}
+	if search.Offset >= len(searchData) {
+		return []models.VanSearchResult{}, pageInfo, nil
+	}
+	searchData = searchData[search.Offset:]
+	if len(searchData) > search.Limit {
+		searchData = searchData[:search.Limit]
+	}
+
+	return searchData, pageInfo, nil
+}
+
+func (v *VanStore) CreateVan(ctx context.Context, vanReq *models.Van) (*models.VanRecord, error) {
+	if err := checkVan(vanReq); err != nil {
+		return nil, err
+	}
+
+	v.db.mu.Lock()
+	defer v.db.mu.Unlock()
+
+	if _, ok := v.db.engines[vanReq.EngineID]; !ok {
+		return nil, foreignKey("van")
+	}
+
+	createdAt := now()
+	van := models.VanRecord{
+		ID:          uuid.New(),
+		Name:        vanReq.Name,
+		Brand:       vanReq.Brand,
+		Description: vanReq.Description,
+		Category:    vanReq.Category,
+		FuelType:    vanReq.FuelType,
+		EngineID:    vanReq.EngineID,
+		Price:       vanReq.Price,
+		ImageURL:    vanReq.ImageURL,
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+	}
+	v.db.vans[van.ID] = van
+	return &van, nil
+}
+
+// Only non-zero fields are written, like the partial UPDATE built by the Postgres store
+func (v *VanStore) UpdateVan(ctx context.Context, id string, vanReq *models.Van) (int64, error) {
+	vanID, err := parseID(id, "van")
+	if err != nil {
+		return -1, err
+	}
+	if err := checkVan(vanReq); err != nil {
+		return -1, err
+	}
+
+	v.db.mu.Lock()
+	defer v.db.mu.Unlock()
+
+	van, ok := v.db.vans[vanID]
+	if !ok {
+		return 0, notFound("van")
+	}
+
+	if vanReq.Name != "" {
+		van.Name = vanReq.Name
+	}
+	if vanReq.Brand != "" {
+		van.Brand = vanReq.Brand
+	}
+	if vanReq.Description != "" {
+		van.Description = vanReq.Description
+	}
+	if vanReq.Category != "" {
+		van.Category = vanReq.Category
+	}
+	if vanReq.FuelType != "" {
+		van.FuelType = vanReq.FuelType
+	}
+	if vanReq.EngineID.Version() == 4 {
+		if _, ok := v.db.engines[vanReq.EngineID]; !ok {
+			return -1, foreignKey("van")
+		}
+		van.EngineID = vanReq.EngineID
+	}
+	if vanReq.Price > 0 {
+		van.Price = vanReq.Price
+	}
+	if vanReq.ImageURL != "" {
+		van.ImageURL = vanReq.ImageURL
+	}
+	van.UpdatedAt = now()
+
+	v.db.vans[vanID] = van
+	return 1, nil
+}
+
+func (v *VanStore) DeleteVan(ctx context.Context, id string) (int64, error) {
+	vanID, err := parseID(id, "van")
+	if err != nil {
+		return -1, err
+	}
+
+	v.db.mu.Lock()
+	defer v.db.mu.Unlock()
+
+	if _, ok := v.db.vans[vanID]; !ok {
+		return 0, notFound("van")
+	}
+	delete(v.db.vans, vanID)
+	return 1, nil
+}
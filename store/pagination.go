@@ -0,0 +1,63 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+)
+
+// Implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// A column that list queries can be ordered by, with the SQL type used to
+// compare a cursor value against it
+type sortColumn struct {
+	name string
+	cast string
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// Build the paged SELECT and the matching COUNT query for a list request.
+// One row more than the limit is fetched so the caller can tell whether a next page exists.
+func buildListQueries(table string, columns string, idColumn string, sort sortColumn, conditions []string, args []interface{}, opts models.ListOptions) (string, []interface{}, string, []interface{}) {
+
+	countQuery := "SELECT COUNT(*) FROM " + table + whereClause(conditions)
+	countArgs := append([]interface{}{}, args...)
+
+	listConditions := append([]string{}, conditions...)
+	listArgs := append([]interface{}{}, args...)
+
+	comparison, direction := ">", "ASC"
+	if opts.Order == "desc" {
+		comparison, direction = "<", "DESC"
+	}
+
+	// keyset condition, the id breaks ties between rows sharing a sort value
+	if opts.After != nil {
+		listArgs = append(listArgs, opts.After.Value, opts.After.ID)
+		listConditions = append(listConditions, fmt.Sprintf("(%s, %s) %s ($%d::%s, $%d::uuid)",
+			sort.name, idColumn, comparison, len(listArgs)-1, sort.cast, len(listArgs)))
+	}
+
+	var query strings.Builder
+	query.WriteString("SELECT " + columns + " FROM " + table + whereClause(listConditions))
+	query.WriteString(fmt.Sprintf(" ORDER BY %s %s, %s %s", sort.name, direction, idColumn, direction))
+
+	listArgs = append(listArgs, opts.Limit+1)
+	query.WriteString(fmt.Sprintf(" LIMIT $%d", len(listArgs)))
+	if opts.Offset > 0 {
+		listArgs = append(listArgs, opts.Offset)
+		query.WriteString(fmt.Sprintf(" OFFSET $%d", len(listArgs)))
+	}
+
+	return query.String(), listArgs, countQuery, countArgs
+}
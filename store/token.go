@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+)
+
+// Column list matching the field order scanned by scanRefreshToken
+const refreshTokenColumns = "id, user_id, family_id, expires_at, used_at, revoked_at, created_at"
+
+type TokenStore struct {
+	db tracedDB
+}
+
+// Constructor method for db variable
+func NewTokenStore(db *sql.DB) *TokenStore {
+	return &TokenStore{db: tracedDB{DB: db}}
+}
+
+func scanRefreshToken(row *sql.Row) (*models.RefreshTokenRecord, error) {
+	var token models.RefreshTokenRecord
+	var usedAt, revokedAt sql.NullTime
+	err := row.Scan(&token.ID, &token.UserID, &token.FamilyID, &token.ExpiresAt, &usedAt, &revokedAt, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	return &token, nil
+}
+
+func (t TokenStore) CreateRefreshToken(ctx context.Context, userID uuid.UUID, familyID uuid.UUID, tokenHash string, expiresAt time.Time) error {
+	var query string = "INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at) VALUES ($1, $2, $3, $4)"
+	_, err := t.db.ExecContext(ctx, query, userID, familyID, tokenHash, expiresAt.UTC())
+	if err != nil {
+		logQueryError(ctx, "Error while inserting data", err)
+		return translateError(err, "refresh token")
+	}
+	return nil
+}
+
+func (t TokenStore) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshTokenRecord, error) {
+	token, err := scanRefreshToken(t.db.QueryRowContext(ctx, "SELECT "+refreshTokenColumns+" FROM refresh_tokens WHERE token_hash=$1", tokenHash))
+	if err != nil {
+		return nil, translateError(err, "refresh token")
+	}
+	return token, nil
+}
+
+// UseRefreshToken atomically marks a live token as used so it can be exchanged only once.
+// Returns ErrNotFound when the token is unknown, expired, revoked or already used.
+func (t TokenStore) UseRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshTokenRecord, error) {
+	var query string = `UPDATE refresh_tokens SET used_at=CURRENT_TIMESTAMP
+		WHERE token_hash=$1 AND used_at IS NULL AND revoked_at IS NULL AND expires_at > $2
+		RETURNING ` + refreshTokenColumns
+	token, err := scanRefreshToken(t.db.QueryRowContext(ctx, query, tokenHash, time.Now().UTC()))
+	if err != nil {
+		return nil, translateError(err, "refresh token")
+	}
+	return token, nil
+}
+
+func (t TokenStore) RevokeRefreshFamily(ctx context.Context, familyID uuid.UUID) (int64, error) {
+	result, err := t.db.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at=CURRENT_TIMESTAMP WHERE family_id=$1 AND revoked_at IS NULL", familyID)
+	if err != nil {
+		logQueryError(ctx, "Error while updating data", err)
+		return -1, err
+	}
+	return result.RowsAffected()
+}
+
+func (t TokenStore) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result, err := t.db.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at=CURRENT_TIMESTAMP WHERE user_id=$1 AND revoked_at IS NULL", userID)
+	if err != nil {
+		logQueryError(ctx, "Error while updating data", err)
+		return -1, err
+	}
+	return result.RowsAffected()
+}
+
+func (t TokenStore) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+
+	// DB transaction
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() { endTx(ctx, tx, err) }()
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING", jti, expiresAt.UTC())
+	if err != nil {
+		logQueryError(ctx, "Error while inserting data", err)
+		return err
+	}
+
+	// expired tokens are rejected anyway, keep the denylist small
+	_, err = tx.ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at < $1", time.Now().UTC())
+	return err
+}
+
+func (t TokenStore) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	err := t.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti=$1)", jti).Scan(&revoked)
+	return revoked, err
+}
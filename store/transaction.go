@@ -0,0 +1,27 @@
+package store
+
+import (
+	"context"
+
+	"github.com/harshitrajsinha/goserver-vanmango/logging"
+	"github.com/harshitrajsinha/goserver-vanmango/metrics"
+)
+
+// endTx is deferred by every store method that opens a transaction. It rolls back
+// when the method failed and commits otherwise, counting the outcome for /metrics.
+func endTx(ctx context.Context, tx *tracedTx, err error) {
+	if err != nil {
+		rbErr := tx.Rollback()
+		metrics.ObserveTransaction(metrics.TxRollback, rbErr)
+		if rbErr != nil {
+			logging.FromContext(ctx).Error("Transaction rollback error", "error", rbErr)
+		}
+		return
+	}
+
+	cmErr := tx.Commit()
+	metrics.ObserveTransaction(metrics.TxCommit, cmErr)
+	if cmErr != nil {
+		logging.FromContext(ctx).Error("Transaction commit error", "error", cmErr)
+	}
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/harshitrajsinha/goserver-vanmango/models"
+)
+
+// Column list matching the field order scanned by scanUser
+const userColumns = "id, username, password_hash, role, created_at, updated_at"
+
+type UserStore struct {
+	db tracedDB
+}
+
+// Constructor method for db variable
+func NewUserStore(db *sql.DB) *UserStore {
+	return &UserStore{db: tracedDB{DB: db}}
+}
+
+func scanUser(row *sql.Row) (*models.UserRecord, error) {
+	var user models.UserRecord
+	err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (u UserStore) GetUserByID(ctx context.Context, id string) (*models.UserRecord, error) {
+	user, err := scanUser(u.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE id=$1", id))
+	if err != nil {
+		return nil, translateError(err, "user")
+	}
+	return user, nil
+}
+
+// Username matching is case-insensitive
+func (u UserStore) GetUserByUsername(ctx context.Context, username string) (*models.UserRecord, error) {
+	user, err := scanUser(u.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE LOWER(username)=LOWER($1)", username))
+	if err != nil {
+		return nil, translateError(err, "user")
+	}
+	return user, nil
+}
+
+func (u UserStore) CreateUser(ctx context.Context, username string, passwordHash string, role models.Role) (*models.UserRecord, error) {
+
+	// DB transaction
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		logQueryError(ctx, "Error while inserting data", err)
+		return nil, translateError(err, "user")
+	}
+
+	defer func() { endTx(ctx, tx, err) }()
+
+	var query string = "INSERT INTO users (username, password_hash, role) VALUES ($1, $2, $3) RETURNING " + userColumns
+	user, err := scanUser(tx.QueryRowContext(ctx, query, username, passwordHash, role))
+	if err != nil {
+		logQueryError(ctx, "Error while inserting data", err)
+		return nil, translateError(err, "user")
+	}
+
+	return user, nil
+}
+
+func (u UserStore) UpdatePassword(ctx context.Context, id string, passwordHash string) (int64, error) {
+
+	// DB transaction
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		logQueryError(ctx, "Error while updating data", err)
+		return -1, translateError(err, "user")
+	}
+
+	defer func() { endTx(ctx, tx, err) }()
+
+	result, err := tx.ExecContext(ctx, "UPDATE users SET password_hash=$1 WHERE id=$2", passwordHash, id)
+	if err != nil {
+		logQueryError(ctx, "Error while updating data", err)
+		return -1, translateError(err, "user")
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return -1, err
+	}
+	if rowAffected == 0 {
+		return 0, notFound("user")
+	}
+	return rowAffected, nil
+}
+
+func (u UserStore) UpdateRole(ctx context.Context, id string, role models.Role) (int64, error) {
+
+	// DB transaction
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		logQueryError(ctx, "Error while updating data", err)
+		return -1, translateError(err, "user")
+	}
+
+	defer func() { endTx(ctx, tx, err) }()
+
+	result, err := tx.ExecContext(ctx, "UPDATE users SET role=$1 WHERE id=$2", role, id)
+	if err != nil {
+		logQueryError(ctx, "Error while updating data", err)
+		return -1, translateError(err, "user")
+	}
+
+	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return -1, err
+	}
+	if rowAffected == 0 {
+		return 0, notFound("user")
+	}
+	return rowAffected, nil
+}
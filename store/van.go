@@ -3,150 +3,220 @@ package store
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
-	"log"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/google/uuid"
 	"github.com/harshitrajsinha/goserver-vanmango/models"
 )
 
-type vanQueryResponse struct {
-	VanID       uuid.UUID `json:"van-id"`
-	Name        string    `json:"name"`
-	Brand       string    `json:"brand"`
-	Description string    `json:"description"`
-	Category    string    `json:"category"`
-	FuelType    string    `json:"fuel-type"`
-	EngineID    string    `json:"engine-id"`
-	Price       int64     `json:"price"`
-	Image       string    `json:"image-url"`
-	CreatedAt   string    `json:"-"`
-	UpdatedAt   string    `json:"-"`
+// Column list matching the field order scanned by scanVan
+const vanColumns = "van_id, name, brand, description, category, fuel_type, engine_id, price, image_url, created_at, updated_at"
+
+// Columns van lists can be sorted by, keyed by the sort query value
+var vanSortColumns = map[string]sortColumn{
+	"created_at": {name: "created_at", cast: "timestamp"},
+	"price":      {name: "price", cast: "bigint"},
+	"name":       {name: "name", cast: "text"},
 }
 
 type VanStore struct {
-	db *sql.DB
+	db tracedDB
 }
 
 func NewVanStore(db *sql.DB) VanStore {
-	return VanStore{db: db}
+	return VanStore{db: tracedDB{DB: db}}
 }
 
-// Query for van price > or < or range
+func scanVan(row rowScanner, van *models.VanRecord, extra ...interface{}) error {
+	dest := []interface{}{&van.ID, &van.Name, &van.Brand, &van.Description, &van.Category, &van.FuelType, &van.EngineID, &van.Price, &van.ImageURL, &van.CreatedAt, &van.UpdatedAt}
+	return row.Scan(append(dest, extra...)...)
+}
 
-func (v VanStore) GetVanById(ctx context.Context, id string) (interface{}, error) {
-	var queryData vanQueryResponse
+func (v VanStore) GetVanById(ctx context.Context, id string) (*models.VanRecord, error) {
+	var queryData models.VanRecord
 
 	// DB transaction
 	tx, err := v.db.BeginTx(ctx, nil)
 	if err != nil {
-		return vanQueryResponse{}, err
+		return nil, translateError(err, "van")
 	}
 
-	defer func() {
-		if err != nil {
-			if rbErr := tx.Rollback(); rbErr != nil {
-				log.Println("Transaction rollback error: ", rbErr)
-			}
-		} else {
-			if cmErr := tx.Commit(); cmErr != nil {
-				log.Println("Commit rollback error: ", cmErr)
-			}
-		}
-	}()
-	err = tx.QueryRowContext(ctx, "SELECT * FROM van WHERE van_id=$1", id).Scan(
-		&queryData.VanID, &queryData.Name, &queryData.Brand, &queryData.Description, &queryData.Category, &queryData.FuelType, &queryData.EngineID, &queryData.Price, &queryData.Image, &queryData.CreatedAt, &queryData.UpdatedAt)
+	defer func() { endTx(ctx, tx, err) }()
+	err = scanVan(tx.QueryRowContext(ctx, "SELECT "+vanColumns+" FROM van WHERE van_id=$1", id), &queryData)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return vanQueryResponse{}, err // return empty model
-		}
-		return vanQueryResponse{}, err // return empty model
+		return nil, translateError(err, "van") // ErrNotFound when no row matches
 	}
-	return queryData, nil
+	return &queryData, nil
 }
 
-func (v VanStore) GetAllVan(ctx context.Context) (interface{}, error) {
+func (v VanStore) GetAllVan(ctx context.Context, filter *models.VanFilter) ([]models.VanRecord, *models.PageInfo, error) {
 
 	// DB transaction
 	tx, err := v.db.BeginTx(ctx, nil)
 	if err != nil {
-		return vanQueryResponse{}, err
+		return nil, nil, translateError(err, "van")
 	}
 
-	defer func() {
-		if err != nil {
-			if rbErr := tx.Rollback(); rbErr != nil {
-				log.Println("Transaction rollback error: ", rbErr)
-			}
-		} else {
-			if cmErr := tx.Commit(); cmErr != nil {
-				log.Println("Commit rollback error: ", cmErr)
-			}
-		}
-	}()
-	rows, err := tx.QueryContext(ctx, "SELECT * FROM van;")
+	defer func() { endTx(ctx, tx, err) }()
+
+	conditions, args := vanFilterConditions(filter)
+	listQuery, listArgs, countQuery, countArgs := buildListQueries("van", vanColumns, "van_id", vanSortColumns[filter.Sort], conditions, args, filter.ListOptions)
+
+	pageInfo := &models.PageInfo{Limit: filter.Limit, Offset: filter.Offset}
+	err = tx.QueryRowContext(ctx, countQuery, countArgs...).Scan(&pageInfo.Total)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return vanQueryResponse{}, err // return empty model
-		}
-		return vanQueryResponse{}, err // return empty model
+		return nil, nil, translateError(err, "van")
+	}
+
+	rows, err := tx.QueryContext(ctx, listQuery, listArgs...)
+	if err != nil {
+		return nil, nil, translateError(err, "van")
 	}
 	defer rows.Close()
 
 	// slice to store all rows
-	vanData := make([]interface{}, 0)
+	vanData := make([]models.VanRecord, 0)
 
 	// Get each row data into a slice
 	for rows.Next() {
-		var queryData vanQueryResponse
-		if err := rows.Scan(
-			&queryData.VanID, &queryData.Name, &queryData.Brand, &queryData.Description, &queryData.Category, &queryData.FuelType, &queryData.EngineID, &queryData.Price, &queryData.Image, &queryData.CreatedAt, &queryData.UpdatedAt); err != nil {
-			log.Fatal(err)
+		var queryData models.VanRecord
+		err = scanVan(rows, &queryData)
+		if err != nil {
+			return nil, nil, translateError(err, "van")
 		}
 		vanData = append(vanData, queryData)
 	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, translateError(err, "van")
+	}
+
+	// an extra row means there is a next page, hand out a cursor pointing at the last row returned
+	if len(vanData) > filter.Limit {
+		vanData = vanData[:filter.Limit]
+		last := vanData[len(vanData)-1]
+		pageInfo.NextCursor = models.Cursor{Sort: filter.Sort, Order: filter.Order, Value: vanSortValue(last, filter.Sort), ID: last.ID}.Encode()
+	}
+
+	return vanData, pageInfo, nil
+}
+
+// Build WHERE conditions for the van list filters
+func vanFilterConditions(filter *models.VanFilter) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Category != "" {
+		args = append(args, strings.ToLower(filter.Category))
+		conditions = append(conditions, fmt.Sprintf("category=$%d", len(args)))
+	}
+	if filter.FuelType != "" {
+		args = append(args, strings.ToLower(filter.FuelType))
+		conditions = append(conditions, fmt.Sprintf("fuel_type=$%d", len(args)))
+	}
+	if filter.Brand != "" {
+		args = append(args, filter.Brand)
+		conditions = append(conditions, fmt.Sprintf("LOWER(brand)=LOWER($%d)", len(args)))
+	}
+	if filter.EngineID != "" {
+		args = append(args, filter.EngineID)
+		conditions = append(conditions, fmt.Sprintf("engine_id=$%d", len(args)))
+	}
+	if filter.MinPrice > 0 {
+		args = append(args, filter.MinPrice)
+		conditions = append(conditions, fmt.Sprintf("price>=$%d", len(args)))
+	}
+	if filter.MaxPrice > 0 {
+		args = append(args, filter.MaxPrice)
+		conditions = append(conditions, fmt.Sprintf("price<=$%d", len(args)))
+	}
 
-	return vanData, nil
+	return conditions, args
 }
 
-func (v VanStore) CreateVan(ctx context.Context, vanReq *models.Van) (int64, error) {
+// Value of the sort column for a row, as stored in a cursor
+func vanSortValue(van models.VanRecord, sort string) string {
+	switch sort {
+	case "price":
+		return strconv.FormatInt(van.Price, 10)
+	case "name":
+		return van.Name
+	default:
+		return van.CreatedAt.Format(time.RFC3339Nano)
+	}
+}
+
+func (v VanStore) SearchVan(ctx context.Context, search *models.VanSearch) ([]models.VanSearchResult, *models.PageInfo, error) {
 
 	// DB transaction
 	tx, err := v.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Println("Error while inserting data ", err)
-		return -1, err
+		return nil, nil, translateError(err, "van")
+	}
+
+	defer func() { endTx(ctx, tx, err) }()
+
+	pageInfo := &models.PageInfo{Limit: search.Limit, Offset: search.Offset}
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM van, websearch_to_tsquery('english', $1) query WHERE search_vector @@ query", search.Query).Scan(&pageInfo.Total)
+	if err != nil {
+		return nil, nil, translateError(err, "van")
 	}
 
-	defer func() {
+	// rank on the weighted vector, snippet highlights matches with <mark> tags
+	var query string = `SELECT ` + vanColumns + `,
+		ts_rank(search_vector, query) AS rank,
+		ts_headline('english', name || ' - ' || brand || ': ' || description, query,
+			'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2') AS snippet
+		FROM van, websearch_to_tsquery('english', $1) query
+		WHERE search_vector @@ query
+		ORDER BY rank DESC, van_id
+		LIMIT $2 OFFSET $3`
+	rows, err := tx.QueryContext(ctx, query, search.Query, search.Limit, search.Offset)
+	if err != nil {
+		return nil, nil, translateError(err, "van")
+	}
+	defer rows.Close()
+
+	// slice to store all rows
+	searchData := make([]models.VanSearchResult, 0)
+
+	for rows.Next() {
+		var result models.VanSearchResult
+		err = scanVan(rows, &result.VanRecord, &result.Rank, &result.Snippet)
 		if err != nil {
-			if rbErr := tx.Rollback(); rbErr != nil {
-				log.Println("Transaction rollback error: ", rbErr)
-			}
-		} else {
-			if cmErr := tx.Commit(); cmErr != nil {
-				log.Println("Commit rollback error: ", cmErr)
-			}
+			return nil, nil, translateError(err, "van")
 		}
-	}()
+		searchData = append(searchData, result)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, translateError(err, "van")
+	}
 
-	var query string = "INSERT INTO van (name, brand, description, category, fuel_type, engine_id, price, image_url) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
-	result, err := tx.ExecContext(ctx, query, vanReq.Name, vanReq.Brand, vanReq.Description, vanReq.Category, vanReq.FuelType, vanReq.EngineID, vanReq.Price, vanReq.ImageURL)
+	return searchData, pageInfo, nil
+}
+
+func (v VanStore) CreateVan(ctx context.Context, vanReq *models.Van) (*models.VanRecord, error) {
+	var createdVan models.VanRecord
 
+	// DB transaction
+	tx, err := v.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Println("Error while inserting data ", err)
-		return -1, err
+		logQueryError(ctx, "Error while inserting data", err)
+		return nil, translateError(err, "van")
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	defer func() { endTx(ctx, tx, err) }()
+
+	var query string = "INSERT INTO van (name, brand, description, category, fuel_type, engine_id, price, image_url) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING " + vanColumns
+	err = scanVan(tx.QueryRowContext(ctx, query, vanReq.Name, vanReq.Brand, vanReq.Description, vanReq.Category, vanReq.FuelType, vanReq.EngineID, vanReq.Price, vanReq.ImageURL), &createdVan)
+
 	if err != nil {
-		log.Println("Error while inserting data ", err)
-		return -1, err
+		logQueryError(ctx, "Error while inserting data", err)
+		return nil, translateError(err, "van")
 	}
 
-	return rowsAffected, nil
+	return &createdVan, nil
 }
 
 func (v VanStore) UpdateVan(ctx context.Context, vanID string, vanReq *models.Van) (int64, error) {
@@ -154,21 +224,11 @@ func (v VanStore) UpdateVan(ctx context.Context, vanID string, vanReq *models.Va
 	// DB transaction
 	tx, err := v.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Println("Error while updating data ", err)
-		return -1, err
+		logQueryError(ctx, "Error while updating data", err)
+		return -1, translateError(err, "van")
 	}
 
-	defer func() {
-		if err != nil {
-			if rbErr := tx.Rollback(); rbErr != nil {
-				fmt.Printf("Transaction rollback error: %v\n", rbErr)
-			}
-		} else {
-			if cmErr := tx.Commit(); cmErr != nil {
-				fmt.Printf("Transaction commit error: %v\n", cmErr)
-			}
-		}
-	}()
+	defer func() { endTx(ctx, tx, err) }()
 
 	var query strings.Builder
 	var args []interface{}
@@ -243,11 +303,18 @@ func (v VanStore) UpdateVan(ctx context.Context, vanID string, vanReq *models.Va
 
 	result, err := tx.ExecContext(ctx, query.String(), args...)
 	if err != nil {
-		log.Println("Error while updating data ", err)
-		return -1, err
+		logQueryError(ctx, "Error while updating data", err)
+		return -1, translateError(err, "van")
 	}
 
 	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return -1, err
+	}
+	if rowAffected == 0 {
+		return 0, notFound("van")
+	}
+
 	return rowAffected, nil
 }
 
@@ -256,28 +323,23 @@ func (v VanStore) DeleteVan(ctx context.Context, id string) (int64, error) {
 	// DB transaction
 	tx, err := v.db.BeginTx(ctx, nil)
 	if err != nil {
-		return -1, err
+		return -1, translateError(err, "van")
 	}
 
-	defer func() {
-		if err != nil {
-			if rbErr := tx.Rollback(); rbErr != nil {
-				log.Println("Transaction rollback error: ", rbErr)
-			}
-		} else {
-			if cmErr := tx.Commit(); cmErr != nil {
-				log.Println("Commit rollback error: ", cmErr)
-			}
-		}
-	}()
+	defer func() { endTx(ctx, tx, err) }()
 
 	var query string = "DELETE FROM van WHERE van_id=$1"
 	result, err := tx.ExecContext(ctx, query, id)
 	if err != nil {
-		return -1, err
+		return -1, translateError(err, "van")
 	}
 	rowAffected, err := result.RowsAffected()
+	if err != nil {
+		return -1, err
+	}
+	if rowAffected == 0 {
+		return 0, notFound("van")
+	}
 
 	return rowAffected, nil
-
 }
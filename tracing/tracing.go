@@ -0,0 +1,131 @@
+// Package tracing sets up OpenTelemetry for the server and offers the small
+// helpers the handler, service and store layers use to record spans.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/harshitrajsinha/goserver-vanmango/buildinfo"
+	"github.com/harshitrajsinha/goserver-vanmango/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	serviceName         = "vanmango"
+	instrumentationName = "github.com/harshitrajsinha/goserver-vanmango"
+)
+
+// W3C traceparent/tracestate and baggage headers
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Setup installs the W3C trace context propagator and, unless the exporter is
+// "none", a tracer provider exporting to OTLP or to stdout/a file. The returned
+// function flushes pending spans and must be called before the process exits.
+func Setup(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+
+	// outgoing calls made with the global propagator carry the trace on too
+	otel.SetTextMapPropagator(propagator)
+
+	if cfg.Exporter == config.TracingExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(serviceName), semconv.ServiceVersion(buildinfo.Get().Commit)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("building trace resource: %v", err)
+	}
+
+	var exporter sdktrace.SpanExporter
+	var closeOutput func() error
+	switch cfg.Exporter {
+	case config.TracingExporterOTLP:
+		// endpoint, headers and TLS also follow the standard OTEL_EXPORTER_OTLP_* variables
+		var options []otlptracehttp.Option
+		if cfg.OTLPEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+		}
+		otlpExporter, err := otlptracehttp.New(ctx, options...)
+		if err != nil {
+			return nil, fmt.Errorf("creating OTLP trace exporter: %v", err)
+		}
+		exporter = otlpExporter
+
+	case config.TracingExporterStdout:
+		var output io.Writer = os.Stdout
+		if cfg.File != "" {
+			file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				return nil, fmt.Errorf("opening trace file: %v", err)
+			}
+			output = file
+			closeOutput = file.Close
+		}
+		stdoutExporter, err := stdouttrace.New(stdouttrace.WithWriter(output))
+		if err != nil {
+			return nil, fmt.Errorf("creating stdout trace exporter: %v", err)
+		}
+		exporter = stdoutExporter
+
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		// a sampled caller keeps the whole trace, new traces are sampled by ratio
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeOutput != nil {
+			if closeErr := closeOutput(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
+
+// Extract returns ctx joined to the trace described by the traceparent header, if any.
+// It works whether or not Setup has run, so a caller's trace ID is never dropped.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Start begins a span named name as a child of any span in ctx
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// RecordError marks the span as failed with err
+func RecordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// End records err on the span, if any, and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		RecordError(span, err)
+	}
+	span.End()
+}